@@ -0,0 +1,170 @@
+package ltspice
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+)
+
+// Batch runs many simulation jobs in parallel, e.g. the variants of a parameter sweep or a
+// Monte Carlo analysis.
+//
+// Example usage:
+//
+//	b := &ltspice.Batch{
+//	    Simulator: &ltspice.LTSpice{Executable: "ltspice"},
+//	    Workers:   4,
+//	    Retries:   []string{"gminsteps=100", "method=gear"},
+//	    Progress:  progress,
+//	}
+//	for _, r := range b.Run(ctx, jobs) {
+//	    if r.Err != nil {
+//	        log.Printf("%s failed after %d attempts: %v", r.Job.Name, r.Attempts, r.Err)
+//	    }
+//	}
+type Batch struct {
+	Simulator Simulator
+	// Workers is the maximum number of simulations running at the same time. Defaults to the number of CPUs.
+	Workers int
+	// Retries holds alternative .options which are tried in order if a job fails with a convergence error,
+	// e.g. "gminsteps=100" or "method=gear". Each entry is added as a ".options" line to the netlist.
+	Retries []string
+	// Progress receives an event whenever a job starts, is retried or finishes. Sends block,
+	// so the channel must be drained while Run is running. The channel is not closed by Run.
+	Progress chan<- Progress
+}
+
+// JobResult is the outcome of a single job of a batch.
+type JobResult struct {
+	// Index is the index of the job in the slice passed to Run.
+	Index int
+	Job   Job
+	// Result holds the results of the last attempt. It may be set even if Err is not nil.
+	Result *Result
+	// Attempts is the number of times the job was simulated.
+	Attempts int
+	Err      error
+}
+
+// JobState is the state of a job reported in a Progress event.
+type JobState int
+
+const (
+	JobStarted JobState = iota
+	JobRetrying
+	JobSucceeded
+	JobFailed
+)
+
+func (s JobState) String() string {
+	return [...]string{"started", "retrying", "succeeded", "failed"}[s]
+}
+
+// Progress is an event sent while a batch is running.
+type Progress struct {
+	Index   int
+	Name    string
+	State   JobState
+	Attempt int
+	// Err is the error of the attempt for JobRetrying and JobFailed events.
+	Err error
+	// Done is the number of finished jobs and Total the number of jobs in the batch.
+	Done  int
+	Total int
+}
+
+// Run simulates all jobs using a bounded pool of workers and returns their results in the order of jobs.
+//
+// Jobs failing with ErrConvergence are retried with the alternative options in Retries. If ctx is
+// cancelled, jobs that have not been started fail with the context error.
+func (b *Batch) Run(ctx context.Context, jobs []Job) []JobResult {
+	workers := b.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	results := make([]JobResult, len(jobs))
+	queue := make(chan int)
+
+	var mu sync.Mutex
+	done := 0
+	report := func(p Progress) {
+		if b.Progress == nil {
+			return
+		}
+		// events are sent while holding the lock so Done never decreases
+		mu.Lock()
+		defer mu.Unlock()
+		if p.State == JobSucceeded || p.State == JobFailed {
+			done++
+		}
+		p.Done, p.Total = done, len(jobs)
+		select {
+		case b.Progress <- p:
+		case <-ctx.Done():
+		}
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				results[i] = b.runJob(ctx, i, jobs[i], report)
+			}
+		}()
+	}
+
+	for i := range jobs {
+		select {
+		case queue <- i:
+		case <-ctx.Done():
+			results[i] = JobResult{Index: i, Job: jobs[i], Err: ctx.Err()}
+		}
+	}
+	close(queue)
+	wg.Wait()
+	return results
+}
+
+func (b *Batch) runJob(ctx context.Context, index int, job Job, report func(Progress)) JobResult {
+	r := JobResult{Index: index, Job: job}
+	report(Progress{Index: index, Name: job.Name, State: JobStarted, Attempt: 1})
+	for {
+		attempt := job
+		if r.Attempts > 0 {
+			attempt.Netlist = withOptions(job.Netlist, b.Retries[r.Attempts-1])
+		}
+		r.Attempts++
+		r.Result, r.Err = b.Simulator.Simulate(ctx, attempt)
+		if r.Err == nil {
+			report(Progress{Index: index, Name: job.Name, State: JobSucceeded, Attempt: r.Attempts})
+			return r
+		}
+		if !errors.Is(r.Err, ErrConvergence) || r.Attempts > len(b.Retries) || ctx.Err() != nil {
+			report(Progress{Index: index, Name: job.Name, State: JobFailed, Attempt: r.Attempts, Err: r.Err})
+			return r
+		}
+		report(Progress{Index: index, Name: job.Name, State: JobRetrying, Attempt: r.Attempts, Err: r.Err})
+	}
+}
+
+// withOptions adds a ".options" line to the netlist in front of the ".end" statement.
+func withOptions(netlist []byte, options string) []byte {
+	line := []byte(".options " + options + "\n")
+	lines := bytes.SplitAfter(netlist, []byte("\n"))
+	for i := len(lines) - 1; i >= 0; i-- {
+		if bytes.EqualFold(bytes.TrimSpace(lines[i]), []byte(".end")) {
+			out := append([]byte{}, bytes.Join(lines[:i], nil)...)
+			out = append(out, line...)
+			return append(out, bytes.Join(lines[i:], nil)...)
+		}
+	}
+	out := append([]byte{}, netlist...)
+	if len(out) > 0 && out[len(out)-1] != '\n' {
+		out = append(out, '\n')
+	}
+	return append(out, line...)
+}
@@ -0,0 +1,127 @@
+package ltspice
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubSimulator calls simulate for every job and tracks the number of concurrent runs.
+type stubSimulator struct {
+	simulate func(job Job) (*Result, error)
+	running  atomic.Int32
+	peak     atomic.Int32
+}
+
+func (s *stubSimulator) Simulate(ctx context.Context, job Job) (*Result, error) {
+	n := s.running.Add(1)
+	defer s.running.Add(-1)
+	for {
+		p := s.peak.Load()
+		if n <= p || s.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	select {
+	case <-time.After(5 * time.Millisecond):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return s.simulate(job)
+}
+
+func TestBatchRun(t *testing.T) {
+	stub := &stubSimulator{simulate: func(job Job) (*Result, error) {
+		switch {
+		case strings.Contains(job.Name, "fail"):
+			return nil, fmt.Errorf("%w: netlist error", ErrSimulationFailed)
+		case strings.Contains(job.Name, "stiff") && !bytes.Contains(job.Netlist, []byte("method=gear")):
+			return &Result{Job: job}, fmt.Errorf("%w: %w: time step too small", ErrSimulationFailed, ErrConvergence)
+		}
+		return &Result{Job: job}, nil
+	}}
+
+	var jobs []Job
+	for i := 0; i < 10; i++ {
+		jobs = append(jobs, Job{Name: fmt.Sprintf("run%d", i), Netlist: []byte("V1 in 0 1\n.end\n")})
+	}
+	jobs[3].Name = "stiff"
+	jobs[7].Name = "fail"
+
+	progress := make(chan Progress)
+	var events []Progress
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for p := range progress {
+			events = append(events, p)
+		}
+	}()
+
+	b := &Batch{Simulator: stub, Workers: 3, Retries: []string{"gminsteps=0", "method=gear"}, Progress: progress}
+	results := b.Run(context.Background(), jobs)
+	close(progress)
+	wg.Wait()
+
+	require.Len(t, results, len(jobs))
+	assert.LessOrEqual(t, stub.peak.Load(), int32(3))
+	for i, r := range results {
+		assert.Equal(t, i, r.Index)
+		assert.Equal(t, jobs[i].Name, r.Job.Name)
+	}
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, 1, results[0].Attempts)
+
+	stiff := results[3]
+	assert.NoError(t, stiff.Err)
+	assert.Equal(t, 3, stiff.Attempts)
+	assert.Equal(t, "V1 in 0 1\n.options method=gear\n.end\n", string(stiff.Result.Job.Netlist))
+
+	assert.ErrorIs(t, results[7].Err, ErrSimulationFailed)
+	assert.Equal(t, 1, results[7].Attempts, "only convergence failures are retried")
+
+	states := map[JobState]int{}
+	for _, e := range events {
+		states[e.State]++
+		assert.Equal(t, len(jobs), e.Total)
+	}
+	assert.Equal(t, map[JobState]int{JobStarted: 10, JobRetrying: 2, JobSucceeded: 9, JobFailed: 1}, states)
+	assert.Equal(t, len(jobs), events[len(events)-1].Done)
+}
+
+func TestBatchRunRetriesExhausted(t *testing.T) {
+	stub := &stubSimulator{simulate: func(job Job) (*Result, error) {
+		return nil, fmt.Errorf("%w: singular matrix", ErrConvergence)
+	}}
+	b := &Batch{Simulator: stub, Retries: []string{"method=gear"}}
+	results := b.Run(context.Background(), []Job{{Name: "a"}})
+	assert.ErrorIs(t, results[0].Err, ErrConvergence)
+	assert.Equal(t, 2, results[0].Attempts)
+}
+
+func TestBatchRunCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stub := &stubSimulator{simulate: func(job Job) (*Result, error) {
+		cancel()
+		return &Result{Job: job}, nil
+	}}
+	b := &Batch{Simulator: stub, Workers: 1}
+	results := b.Run(ctx, make([]Job, 5))
+
+	assert.NoError(t, results[0].Err)
+	assert.ErrorIs(t, results[4].Err, context.Canceled)
+}
+
+func TestWithOptions(t *testing.T) {
+	assert.Equal(t, "R1 a b 1k\n.options gminsteps=0\n.END\n", string(withOptions([]byte("R1 a b 1k\n.END\n"), "gminsteps=0")))
+	assert.Equal(t, "R1 a b 1k\n.options method=gear\n", string(withOptions([]byte("R1 a b 1k"), "method=gear")))
+}
@@ -0,0 +1,221 @@
+package ltspice
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// Bode holds the frequency response of a complex trace of an AC analysis.
+type Bode struct {
+	Frequency []float64
+	// Magnitude is in dB.
+	Magnitude []float64
+	// Phase is the unwrapped phase in degrees.
+	Phase []float64
+	// GroupDelay is the negative derivative of the phase with respect to the angular frequency, in seconds.
+	GroupDelay []float64
+}
+
+// NewBode returns the frequency response h sampled at the increasing frequencies freq. It can be used on
+// responses derived from several traces, e.g. V(out)/V(in).
+func NewBode(freq []float64, h []complex128) *Bode {
+	b := &Bode{
+		Frequency:  freq,
+		Magnitude:  make([]float64, len(h)),
+		Phase:      make([]float64, len(h)),
+		GroupDelay: make([]float64, len(h)),
+	}
+	for i, v := range h {
+		b.Magnitude[i] = 20 * math.Log10(cmplx.Abs(v))
+		b.Phase[i] = cmplx.Phase(v) * 180 / math.Pi
+		if i > 0 {
+			// unwrap
+			b.Phase[i] -= 360 * math.Round((b.Phase[i]-b.Phase[i-1])/360)
+		}
+	}
+	n := len(h)
+	for i := range b.GroupDelay {
+		lo, hi := max(0, i-1), min(n-1, i+1)
+		if hi == lo || freq[hi] == freq[lo] {
+			continue
+		}
+		b.GroupDelay[i] = -(b.Phase[hi] - b.Phase[lo]) / (360 * (freq[hi] - freq[lo]))
+	}
+	return b
+}
+
+// Bode returns the frequency response of a complex trace. For stepped simulations a step index selects
+// the step, the first step is used if none is given.
+//
+// Example usage:
+//
+//	bode, err := simData.Bode("V(out)")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	bw, ok := bode.Bandwidth()
+func (sim *SimData) Bode(name string, step ...int) (*Bode, error) {
+	if !sim.Meta.Flags.hasFlag(Complex) {
+		return nil, fmt.Errorf("%w: Bode analysis requires complex data, got %s", ErrInvalidSimulationType, sim.GetType())
+	}
+	trace, err := GetTrace[complex128](sim, name)
+	if err != nil {
+		return nil, err
+	}
+	return NewBode(sim.GetXAxis(step...), trace.GetSignal(step...)), nil
+}
+
+// MiddlebrookLoopGain returns the loop gain measured with Middlebrook's double injection method, as in the
+// LoopGain example of LTSpice. vx and vy are the voltages on both sides of the voltage injection source,
+// ix and iy the currents on both sides of the current injection source. The voltage and current loop gains
+// Tv = -vx/vy and Ti = ix/iy are combined to
+//
+//	T = (Tv*Ti - 1) / (Tv + Ti + 2)
+//
+// All four traces are taken from the same step of sim, so both injections must be part of the same
+// simulation run, e.g. in two identical copies of the circuit as in the LoopGain example. For stepped
+// simulations a step index selects the step, the first step is used if none is given.
+func (sim *SimData) MiddlebrookLoopGain(vx, vy, ix, iy string, step ...int) (*Bode, error) {
+	if !sim.Meta.Flags.hasFlag(Complex) {
+		return nil, fmt.Errorf("%w: loop gain requires complex data, got %s", ErrInvalidSimulationType, sim.GetType())
+	}
+	var signals [4][]complex128
+	for i, name := range []string{vx, vy, ix, iy} {
+		trace, err := GetTrace[complex128](sim, name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, name)
+		}
+		signals[i] = trace.GetSignal(step...)
+	}
+	t := make([]complex128, len(signals[0]))
+	for i := range t {
+		tv := -signals[0][i] / signals[1][i]
+		ti := signals[2][i] / signals[3][i]
+		t[i] = (tv*ti - 1) / (tv + ti + 2)
+	}
+	return NewBode(sim.GetXAxis(step...), t), nil
+}
+
+// DCGain returns the magnitude in dB at the lowest frequency.
+func (b *Bode) DCGain() float64 {
+	if len(b.Magnitude) == 0 {
+		return math.NaN()
+	}
+	return b.Magnitude[0]
+}
+
+// Bandwidth returns the frequency at which the magnitude first drops 3 dB below the DC gain.
+func (b *Bode) Bandwidth() (float64, bool) {
+	return b.crossing(b.Magnitude, b.DCGain()-3)
+}
+
+// UnityGainFrequency returns the frequency at which the magnitude first falls below 0 dB.
+func (b *Bode) UnityGainFrequency() (float64, bool) {
+	return b.crossing(b.Magnitude, 0)
+}
+
+// PhaseMargin returns the distance of the phase from -180° in degrees at the unity gain frequency of
+// a loop gain, together with that frequency.
+func (b *Bode) PhaseMargin() (float64, float64, bool) {
+	f, ok := b.UnityGainFrequency()
+	if !ok {
+		return 0, 0, false
+	}
+	return wrapDegrees(b.at(b.Phase, f) + 180), f, true
+}
+
+// GainMargin returns how far the magnitude is below 0 dB, in dB, at the first frequency at which the phase
+// of a loop gain crosses -180° (modulo 360°), together with that frequency.
+func (b *Bode) GainMargin() (float64, float64, bool) {
+	for i := 1; i < len(b.Phase); i++ {
+		k0 := math.Floor((b.Phase[i-1] + 180) / 360)
+		k1 := math.Floor((b.Phase[i] + 180) / 360)
+		if k0 == k1 {
+			continue
+		}
+		target := 360*math.Max(k0, k1) - 180
+		f := b.interpolateFrequency(i, b.Phase, target)
+		return -b.at(b.Magnitude, f), f, true
+	}
+	return 0, 0, false
+}
+
+// Peaking returns how far the maximum of the magnitude rises above the DC gain, in dB.
+func (b *Bode) Peaking() float64 {
+	peak := math.Inf(-1)
+	for _, m := range b.Magnitude {
+		peak = math.Max(peak, m)
+	}
+	return math.Max(0, peak-b.DCGain())
+}
+
+// RollOff returns the slope of the magnitude in dB/decade over the last decade of the sweep, fitted with
+// least squares.
+func (b *Bode) RollOff() float64 {
+	n := len(b.Frequency)
+	if n < 2 {
+		return math.NaN()
+	}
+	last := b.Frequency[n-1]
+	var sx, sy, sxx, sxy, m float64
+	for i, f := range b.Frequency {
+		if f <= 0 || f < last/10 {
+			continue
+		}
+		x := math.Log10(f)
+		sx += x
+		sy += b.Magnitude[i]
+		sxx += x * x
+		sxy += x * b.Magnitude[i]
+		m++
+	}
+	if m < 2 {
+		return math.NaN()
+	}
+	return (m*sxy - sx*sy) / (m*sxx - sx*sx)
+}
+
+// crossing returns the first frequency at which values falls below level.
+func (b *Bode) crossing(values []float64, level float64) (float64, bool) {
+	for i := 1; i < len(values); i++ {
+		if values[i-1] >= level && values[i] < level {
+			return b.interpolateFrequency(i, values, level), true
+		}
+	}
+	return 0, false
+}
+
+// interpolateFrequency returns the frequency between the points i-1 and i at which values reaches level.
+// The frequency is interpolated logarithmically.
+func (b *Bode) interpolateFrequency(i int, values []float64, level float64) float64 {
+	f0, f1 := b.Frequency[i-1], b.Frequency[i]
+	t := (level - values[i-1]) / (values[i] - values[i-1])
+	if f0 <= 0 {
+		return f0 + t*(f1-f0)
+	}
+	return f0 * math.Pow(f1/f0, t)
+}
+
+// at returns values at the frequency f, interpolated linearly over the logarithm of the frequency.
+func (b *Bode) at(values []float64, f float64) float64 {
+	if f <= 0 || len(b.Frequency) == 0 || b.Frequency[0] <= 0 {
+		return interpLinear(b.Frequency, values, f)
+	}
+	logF := make([]float64, len(b.Frequency))
+	for i, v := range b.Frequency {
+		logF[i] = math.Log10(v)
+	}
+	return interpLinear(logF, values, math.Log10(f))
+}
+
+// wrapDegrees wraps an angle to (-180°, 180°].
+func wrapDegrees(deg float64) float64 {
+	deg = math.Mod(deg, 360)
+	if deg > 180 {
+		deg -= 360
+	} else if deg <= -180 {
+		deg += 360
+	}
+	return deg
+}
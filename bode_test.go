@@ -0,0 +1,136 @@
+package ltspice
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// responseSim returns an AC analysis of h from 1 Hz to 10 MHz with 100 points per decade.
+func responseSim(t *testing.T, h func(s complex128) complex128) *SimData {
+	var f []float64
+	var v []complex128
+	for i := 0; i <= 700; i++ {
+		freq := math.Pow(10, float64(i)/100)
+		f = append(f, freq)
+		v = append(v, h(complex(0, 2*math.Pi*freq)))
+	}
+	fc := make([]complex128, len(f))
+	for i := range f {
+		fc[i] = complex(f[i], 0)
+	}
+	sim, err := newTableSim(ACAnalysis, "response", []string{"frequency", "V(out)"}, nil, [][]complex128{fc, v})
+	require.NoError(t, err)
+	return sim
+}
+
+func TestBodeFirstOrder(t *testing.T) {
+	const fc = 1e3
+	sim := responseSim(t, func(s complex128) complex128 {
+		return 10 / (1 + s/(2*math.Pi*fc))
+	})
+	b, err := sim.Bode("V(out)")
+	require.NoError(t, err)
+	assert.InDelta(t, 20, b.DCGain(), 1e-3)
+	bw, ok := b.Bandwidth()
+	require.True(t, ok)
+	// -3 dB is slightly below the corner frequency
+	assert.InDelta(t, fc*math.Sqrt(math.Pow(10, 0.3)-1), bw, 0.1)
+	ugf, ok := b.UnityGainFrequency()
+	require.True(t, ok)
+	assert.InDelta(t, fc*math.Sqrt(99), ugf, 10)
+	assert.InDelta(t, 0, b.Peaking(), 1e-12)
+	assert.InDelta(t, -20, b.RollOff(), 0.01)
+	assert.InDelta(t, -90, b.Phase[len(b.Phase)-1], 0.1)
+	// group delay at DC is 1/(2π fc)
+	assert.InDelta(t, 1/(2*math.Pi*fc), b.GroupDelay[0], 1e-7)
+
+	_, _, ok = b.GainMargin()
+	assert.False(t, ok)
+}
+
+func TestBodeSecondOrder(t *testing.T) {
+	const f0, q = 1e4, 2.0
+	w0 := 2 * math.Pi * f0
+	sim := responseSim(t, func(s complex128) complex128 {
+		return 1 / (1 + s/complex(q*w0, 0) + s*s/complex(w0*w0, 0))
+	})
+	b, err := sim.Bode("V(out)")
+	require.NoError(t, err)
+	wantPeak := 20 * math.Log10(q/math.Sqrt(1-1/(4*q*q)))
+	assert.InDelta(t, wantPeak, b.Peaking(), 0.01)
+	assert.InDelta(t, -40, b.RollOff(), 0.1)
+	assert.InDelta(t, -180, b.Phase[len(b.Phase)-1], 0.5)
+	bw, ok := b.Bandwidth()
+	require.True(t, ok)
+	assert.Greater(t, bw, f0)
+}
+
+func TestBodeMargins(t *testing.T) {
+	// integrator with two poles: 1e5/(s/(2π) (1 + s/(2π 1e5)) (1 + s/(2π 1e6)))
+	sim := responseSim(t, func(s complex128) complex128 {
+		p1, p2 := complex(2*math.Pi*1e5, 0), complex(2*math.Pi*1e6, 0)
+		return 1e5 / (s / (2 * math.Pi) * (1 + s/p1) * (1 + s/p2))
+	})
+	b, err := sim.Bode("V(out)")
+	require.NoError(t, err)
+
+	pm, fc, ok := b.PhaseMargin()
+	require.True(t, ok)
+	h := func(f float64) complex128 {
+		s := complex(0, 2*math.Pi*f)
+		return 1e5 / (s / (2 * math.Pi) * (1 + s/complex(2*math.Pi*1e5, 0)) * (1 + s/complex(2*math.Pi*1e6, 0)))
+	}
+	assert.InDelta(t, 1, cmplx.Abs(h(fc)), 1e-3)
+	assert.InDelta(t, 180+cmplx.Phase(h(fc))*180/math.Pi, pm, 0.05)
+
+	gm, f180, ok := b.GainMargin()
+	require.True(t, ok)
+	// the phase reaches -180° at sqrt(1e5 * 1e6)
+	assert.InDelta(t, math.Sqrt(1e11), f180, 2e3)
+	assert.InDelta(t, -20*math.Log10(cmplx.Abs(h(f180))), gm, 0.01)
+}
+
+func TestMiddlebrookLoopGain(t *testing.T) {
+	sim, err := Parse("testdata/simulations/ac/Loop-Gain/LoopGain.raw")
+	require.NoError(t, err)
+	b, err := sim.MiddlebrookLoopGain("V(x)", "V(y)", "I(V3)", "I(V4)")
+	require.NoError(t, err)
+	assert.Len(t, b.Frequency, 271)
+	assert.InDelta(t, 108.79, b.DCGain(), 0.01)
+
+	pm, fc, ok := b.PhaseMargin()
+	require.True(t, ok)
+	assert.InDelta(t, 309e3, fc, 1e3)
+	assert.InDelta(t, 73.46, pm, 0.01)
+	gm, f180, ok := b.GainMargin()
+	require.True(t, ok)
+	assert.InDelta(t, 2.135e6, f180, 1e3)
+	assert.InDelta(t, 22.67, gm, 0.01)
+
+	_, err = sim.MiddlebrookLoopGain("V(x)", "V(y)", "I(V3)", "I(missing)")
+	assert.ErrorIs(t, err, ErrTraceDoesNotExist)
+}
+
+func TestBodeErrors(t *testing.T) {
+	sim, err := Parse("testdata/simulations/trans/LM741/LM741.raw")
+	require.NoError(t, err)
+	_, err = sim.Bode("V(n001)")
+	assert.ErrorIs(t, err, ErrInvalidSimulationType)
+
+	ac, err := Parse("testdata/simulations/ac/low-pass/low-pass-filter.raw")
+	require.NoError(t, err)
+	_, err = ac.Bode("V(missing)")
+	assert.ErrorIs(t, err, ErrTraceDoesNotExist)
+
+	empty := NewBode(nil, nil)
+	assert.True(t, math.IsNaN(empty.DCGain()))
+	assert.True(t, math.IsNaN(empty.RollOff()))
+	_, ok := empty.Bandwidth()
+	assert.False(t, ok)
+	assert.Equal(t, 170.0, wrapDegrees(-190))
+	assert.Equal(t, 180.0, wrapDegrees(-180))
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/theadell/ltspice"
+	"github.com/theadell/ltspice/ltarrow"
+)
+
+var exporters = map[string]func(io.Writer, *ltspice.SimData) error{
+	"csv":     ltspice.WriteCSV,
+	"json":    ltspice.WriteJSON,
+	"parquet": ltarrow.WriteParquet,
+	"arrow":   ltarrow.WriteIPC,
+	"npz":     ltspice.WriteNPZ,
+	"mat":     ltspice.WriteMAT,
+}
+
+func exportFormats() string {
+	formats := make([]string, 0, len(exporters))
+	for f := range exporters {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+	return strings.Join(formats, "|")
+}
+
+func runInfo(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("info", stderr)
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	sim, err := ltspice.Parse(positional[0])
+	if err != nil {
+		return err
+	}
+
+	meta := sim.Meta
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "File:\t%s\n", positional[0])
+	fmt.Fprintf(tw, "Title:\t%s\n", meta.Title)
+	fmt.Fprintf(tw, "Date:\t%s\n", meta.Date.Format(time.ANSIC))
+	fmt.Fprintf(tw, "Plot:\t%s\n", meta.SimType)
+	fmt.Fprintf(tw, "Flags:\t%s\n", meta.Flags)
+	fmt.Fprintf(tw, "Command:\t%s\n", meta.Command)
+	fmt.Fprintf(tw, "Points:\t%d\n", meta.NoPoints)
+	fmt.Fprintf(tw, "Steps:\t%d\n", sim.GetSteps())
+	fmt.Fprintf(tw, "Variables:\t%d\n", meta.NoVariables)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	tw = tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	for i, v := range sim.GetVariables() {
+		fmt.Fprintf(tw, "  %d\t%s\t%s\n", i, v.Name, v.Typ)
+	}
+	return tw.Flush()
+}
+
+func runList(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("list", stderr)
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	sim, err := ltspice.Parse(positional[0])
+	if err != nil {
+		return err
+	}
+	for _, v := range sim.GetVariables() {
+		fmt.Fprintln(stdout, v.Name)
+	}
+	return nil
+}
+
+func runExport(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("export", stderr)
+	format := fs.String("format", "csv", "output format: "+exportFormats())
+	output := fs.String("o", "", "output file (default stdout)")
+	var traces stringList
+	fs.Var(&traces, "trace", "trace to export, can be repeated (default all)")
+	step := fs.Int("step", -1, "step to export (default all)")
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+
+	write, ok := exporters[*format]
+	if !ok {
+		return fmt.Errorf("unknown format %q, expected one of %s", *format, exportFormats())
+	}
+	sim, err := load(positional[0], traces, *step)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return write(stdout, sim)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := write(f, sim); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func runCat(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("cat", stderr)
+	var traces stringList
+	fs.Var(&traces, "trace", "trace to print, can be repeated (default all)")
+	step := fs.Int("step", -1, "step to print (default all)")
+	positional, err := parseArgs(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	sim, err := load(positional[0], traces, *step)
+	if err != nil {
+		return err
+	}
+
+	isComplex := sim.Meta.Flags&ltspice.Complex != 0
+	vars := sim.GetVariables()
+	bw := bufio.NewWriter(stdout)
+	header := []string{vars[0].Name}
+	for _, v := range vars[1:] {
+		if isComplex {
+			header = append(header, v.Name+"_re", v.Name+"_im")
+		} else {
+			header = append(header, v.Name)
+		}
+	}
+	fmt.Fprintf(bw, "# %s\n", strings.Join(header, "\t"))
+
+	for s := 0; s < sim.GetSteps(); s++ {
+		if s > 0 {
+			// a blank line separates steps, as expected by gnuplot and similar tools
+			bw.WriteString("\n")
+		}
+		xAxis := sim.GetXAxis(s)
+		columns := make([][]string, 0, len(header)-1)
+		for _, v := range vars[1:] {
+			if isComplex {
+				t, err := ltspice.GetTrace[complex128](sim, v.Name)
+				if err != nil {
+					return err
+				}
+				re, im := make([]string, len(xAxis)), make([]string, len(xAxis))
+				for i, c := range t.GetSignal(s) {
+					re[i], im[i] = formatFloat(real(c)), formatFloat(imag(c))
+				}
+				columns = append(columns, re, im)
+				continue
+			}
+			t, err := ltspice.GetTrace[float64](sim, v.Name)
+			if err != nil {
+				return err
+			}
+			col := make([]string, len(xAxis))
+			for i, f := range t.GetSignal(s) {
+				col[i] = formatFloat(f)
+			}
+			columns = append(columns, col)
+		}
+		for i, x := range xAxis {
+			bw.WriteString(formatFloat(x))
+			for _, col := range columns {
+				bw.WriteByte('\t')
+				bw.WriteString(col[i])
+			}
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+// load parses the raw file and reduces it to the given traces and step.
+// An empty trace list selects all traces, a negative step selects all steps.
+func load(fileName string, traces []string, step int) (*ltspice.SimData, error) {
+	sim, err := ltspice.Parse(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if len(traces) > 0 {
+		if sim, err = sim.SelectTraces(traces...); err != nil {
+			return nil, err
+		}
+	}
+	if step >= 0 {
+		if sim, err = sim.SelectSteps(step); err != nil {
+			return nil, err
+		}
+	}
+	return sim, nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
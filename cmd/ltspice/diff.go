@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/theadell/ltspice"
+)
+
+// errDifferent is returned by the diff command if the simulations deviate beyond the tolerances.
+var errDifferent = errors.New("simulations differ")
+
+func runDiff(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("diff", stderr)
+	absTol := fs.Float64("abstol", 0, "absolute tolerance")
+	relTol := fs.Float64("reltol", 0, "relative tolerance")
+	strict := fs.Bool("strict", false, "fail if variables were added or removed")
+	var traces stringList
+	fs.Var(&traces, "trace", "trace to compare, can be repeated (default all common traces)")
+	positional, err := parseArgs(fs, args, 2)
+	if err != nil {
+		return err
+	}
+
+	a, err := ltspice.Parse(positional[0])
+	if err != nil {
+		return err
+	}
+	b, err := ltspice.Parse(positional[1])
+	if err != nil {
+		return err
+	}
+	opts := ltspice.CompareOptions{Tolerance: ltspice.Tolerance{Abs: *absTol, Rel: *relTol}}
+	if len(traces) > 0 {
+		opts.Traces = make(map[string]*ltspice.Tolerance, len(traces))
+		for _, t := range traces {
+			opts.Traces[t] = nil
+		}
+	}
+	report, err := ltspice.Compare(a, b, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "--- %s\n+++ %s\n", positional[0], positional[1])
+	for _, name := range report.Removed {
+		fmt.Fprintf(stdout, "- %s\n", name)
+	}
+	for _, name := range report.Added {
+		fmt.Fprintf(stdout, "+ %s\n", name)
+	}
+	if report.StepsA != report.StepsB {
+		fmt.Fprintf(stdout, "steps: %d != %d\n", report.StepsA, report.StepsB)
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "TRACE\tSTEP\tPOINTS\tMAX ABS\tAT\tMAX REL\tAT\tFAILURES\n")
+	for _, t := range report.Traces {
+		for _, s := range t.Steps {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%.4g\t%.6g\t%.4g\t%.6g\t%d\n", t.Name, s.Step, s.Points, s.MaxAbs, s.MaxAbsAt, s.MaxRel, s.MaxRelAt, s.Failures)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if !report.Passed() || *strict && len(report.Added)+len(report.Removed) > 0 {
+		return errDifferent
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Command ltspice inspects and converts LTSpice raw files.
+//
+// Usage:
+//
+//	ltspice info   <file.raw>
+//	ltspice list   <file.raw>
+//	ltspice export [--format csv|json|parquet|arrow|npz|mat] [--trace name]... [--step n] [-o file] <file.raw>
+//	ltspice cat    [--trace name]... [--step n] <file.raw>
+//	ltspice diff   [--abstol x] [--reltol x] [--trace name]... [--strict] <a.raw> <b.raw>
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string, stdout, stderr io.Writer) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{name: "info", usage: "info <file.raw>\n\tprint the metadata and variables of a raw file", run: runInfo},
+		{name: "list", usage: "list <file.raw>\n\tprint the variable names of a raw file", run: runList},
+		{name: "export", usage: "export [flags] <file.raw>\n\twrite the simulation data in another format", run: runExport},
+		{name: "cat", usage: "cat [flags] <file.raw>\n\tprint the data points as whitespace separated columns", run: runCat},
+		{name: "diff", usage: "diff [flags] <a.raw> <b.raw>\n\tcompare two simulations, exits with status 1 if they differ", run: runDiff},
+	}
+}
+
+// errUsage signals that the usage of a command has already been printed.
+var errUsage = errors.New("usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		usage(stderr)
+		return 2
+	}
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		err := cmd.run(args[1:], stdout, stderr)
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, errUsage), errors.Is(err, flag.ErrHelp):
+			return 2
+		default:
+			fmt.Fprintf(stderr, "ltspice %s: %v\n", cmd.name, err)
+			return 1
+		}
+	}
+	fmt.Fprintf(stderr, "ltspice: unknown command %q\n", args[0])
+	usage(stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: ltspice <command> [arguments]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s\n", cmd.usage)
+	}
+}
+
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("ltspice "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+// parseArgs parses the flags of fs allowing flags and positional arguments to be interleaved
+// and checks that exactly n positional arguments are given.
+func parseArgs(fs *flag.FlagSet, args []string, n int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) != n {
+		fmt.Fprintf(fs.Output(), "%s: expected %d argument(s), got %d\n", fs.Name(), n, len(positional))
+		fs.Usage()
+		return nil, errUsage
+	}
+	return positional, nil
+}
+
+// stringList is a flag which can be given multiple times.
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
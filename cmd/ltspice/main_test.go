@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	steppedRaw = "../../testdata/simulations/trans/stepped2/TRAN-STEP.raw"
+	acRaw      = "../../testdata/simulations/ac/low-pass/low-pass-filter.raw"
+)
+
+func runCommand(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestInfo(t *testing.T) {
+	code, out, _ := runCommand(t, "info", steppedRaw)
+	require.Equal(t, 0, code)
+	assert.Contains(t, out, "Transient Analysis")
+	assert.Contains(t, out, "Steps:      4")
+	assert.Contains(t, out, "forward|stepped")
+	assert.Contains(t, out, "V(out)  voltage")
+}
+
+func TestList(t *testing.T) {
+	code, out, _ := runCommand(t, "list", acRaw)
+	require.Equal(t, 0, code)
+	assert.Equal(t, "frequency\nV(n001)\nV(n002)\nI(C1)\nI(R1)\nI(V1)\n", out)
+}
+
+func TestExportCSV(t *testing.T) {
+	code, out, stderr := runCommand(t, "export", steppedRaw, "--format", "csv", "--trace", "V(out)", "--step", "2")
+	require.Equal(t, 0, code, stderr)
+
+	records, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"step", "time", "V(out)"}, records[0])
+	assert.Greater(t, len(records), 1)
+}
+
+func TestExportToFile(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "sim.parquet")
+	code, _, stderr := runCommand(t, "export", "--format=parquet", "-o", out, acRaw)
+	require.Equal(t, 0, code, stderr)
+
+	b, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "PAR1", string(b[:4]))
+}
+
+func TestCat(t *testing.T) {
+	code, out, _ := runCommand(t, "cat", "--trace", "V(n002)", acRaw)
+	require.Equal(t, 0, code)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	assert.Equal(t, "# frequency\tV(n002)_re\tV(n002)_im", lines[0])
+	assert.Len(t, lines, 4)
+	assert.Len(t, strings.Split(lines[1], "\t"), 3)
+}
+
+func TestErrors(t *testing.T) {
+	code, _, stderr := runCommand(t)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "Usage")
+
+	code, _, stderr = runCommand(t, "bogus")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "unknown command")
+
+	code, _, _ = runCommand(t, "info")
+	assert.Equal(t, 2, code)
+
+	code, _, stderr = runCommand(t, "export", "--format", "xls", acRaw)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "unknown format")
+
+	code, _, stderr = runCommand(t, "cat", "--trace", "V(nope)", acRaw)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "trace not found")
+}
+
+func TestDiff(t *testing.T) {
+	code, out, stderr := runCommand(t, "diff", steppedRaw, steppedRaw)
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, out, "TRACE")
+	assert.Contains(t, out, "V(out)")
+
+	other := "../../testdata/simulations/trans/stepped/tran-stepped.raw"
+	code, out, stderr = runCommand(t, "diff", "--abstol", "1e9", "--trace", "I(R1)", steppedRaw, other)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, out, "steps: 4 != 2")
+	assert.Contains(t, stderr, "simulations differ")
+
+	code, _, _ = runCommand(t, "diff", steppedRaw)
+	assert.Equal(t, 2, code)
+}
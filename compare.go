@@ -0,0 +1,249 @@
+package ltspice
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sort"
+)
+
+// Tolerance defines the allowed deviation between two traces. A point passes if
+// |a - b| <= Abs + Rel * |a|, where a is the reference value.
+type Tolerance struct {
+	Abs float64
+	Rel float64
+}
+
+// CompareOptions configures Compare.
+type CompareOptions struct {
+	// Tolerance is applied to all traces without an entry in Traces.
+	Tolerance Tolerance
+	// Traces restricts the comparison to the given traces. A nil tolerance value uses the default Tolerance.
+	Traces map[string]*Tolerance
+}
+
+// CompareReport is the result of comparing two simulations.
+type CompareReport struct {
+	// Added lists the variables which only exist in the second simulation.
+	Added []string
+	// Removed lists the variables which only exist in the first simulation.
+	Removed []string
+	// StepsA and StepsB are the number of steps of both simulations. Only the common steps are compared.
+	StepsA int
+	StepsB int
+	// Traces holds the deviations of all compared traces.
+	Traces []TraceDiff
+}
+
+// TraceDiff holds the deviations of a single trace, per step.
+type TraceDiff struct {
+	Name      string
+	Tolerance Tolerance
+	Steps     []StepDiff
+}
+
+// StepDiff holds the deviation of a trace in a single step.
+type StepDiff struct {
+	Step int
+	// Points is the number of compared points.
+	Points int
+	// MaxAbs is the maximum absolute deviation found at x position MaxAbsAt.
+	MaxAbs   float64
+	MaxAbsAt float64
+	// MaxRel is the maximum relative deviation |a - b| / |a| found at x position MaxRelAt.
+	MaxRel   float64
+	MaxRelAt float64
+	// Failures is the number of points exceeding the tolerance.
+	Failures int
+	// Worst holds up to MaxWorstDeviations failing points, the largest absolute deviation first.
+	Worst []Deviation
+}
+
+// MaxWorstDeviations is the number of failing points kept in StepDiff.Worst.
+const MaxWorstDeviations = 5
+
+// Deviation is the deviation of a single point. X is the x position, or the point index for
+// simulations without an x-axis.
+type Deviation struct {
+	X   float64
+	Abs float64
+	Rel float64
+}
+
+// Exceeded reports whether any step of the trace exceeds the tolerance.
+func (d TraceDiff) Exceeded() bool {
+	for _, s := range d.Steps {
+		if s.Failures > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Passed reports whether both simulations have the same number of steps and
+// no compared trace exceeds its tolerance.
+func (r *CompareReport) Passed() bool {
+	if r.StepsA != r.StepsB {
+		return false
+	}
+	for _, t := range r.Traces {
+		if t.Exceeded() {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare compares the traces the simulations a and b have in common, a is the reference.
+//
+// For each step the traces of b are linearly interpolated onto the x-axis of a, points of a
+// outside the x range of b are skipped. Operating point and transfer function simulations
+// have no x-axis and are compared point by point.
+//
+// Example usage:
+//
+//	report, err := ltspice.Compare(golden, sim, ltspice.CompareOptions{
+//	    Tolerance: ltspice.Tolerance{Abs: 1e-6, Rel: 1e-3},
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if !report.Passed() {
+//	    log.Fatal("simulation results deviate from the golden results")
+//	}
+func Compare(a, b *SimData, opts CompareOptions) (*CompareReport, error) {
+	if a.GetType() != b.GetType() {
+		return nil, fmt.Errorf("%w: cannot compare %s with %s", ErrInvalidSimulationType, a.GetType(), b.GetType())
+	}
+	if a.Meta.Flags.hasFlag(Complex) != b.Meta.Flags.hasFlag(Complex) {
+		return nil, fmt.Errorf("%w: cannot compare real with complex data", ErrInvalidSimulationType)
+	}
+
+	report := &CompareReport{StepsA: a.GetSteps(), StepsB: b.GetSteps()}
+	inA := make(map[string]bool, len(a.Meta.Variables))
+	inB := make(map[string]bool, len(b.Meta.Variables))
+	for _, v := range a.Meta.Variables[1:] {
+		inA[v.Name] = true
+	}
+	for _, v := range b.Meta.Variables[1:] {
+		inB[v.Name] = true
+		if !inA[v.Name] {
+			report.Added = append(report.Added, v.Name)
+		}
+	}
+
+	var names []string
+	for _, v := range a.Meta.Variables[1:] {
+		if !inB[v.Name] {
+			report.Removed = append(report.Removed, v.Name)
+			continue
+		}
+		if opts.Traces == nil {
+			names = append(names, v.Name)
+		}
+	}
+	for name := range opts.Traces {
+		if !inA[name] || !inB[name] {
+			return nil, fmt.Errorf("%w: %s is not part of both simulations", ErrTraceDoesNotExist, name)
+		}
+		names = append(names, name)
+	}
+	if opts.Traces != nil {
+		sort.Strings(names)
+	}
+
+	byIndex := a.GetType() == OperatingPoint || a.GetType() == TransferFunction
+	steps := min(report.StepsA, report.StepsB)
+	for _, name := range names {
+		tol := opts.Tolerance
+		if t := opts.Traces[name]; t != nil {
+			tol = *t
+		}
+		diff := TraceDiff{Name: name, Tolerance: tol}
+		for step := 0; step < steps; step++ {
+			var d StepDiff
+			if a.Meta.Flags.hasFlag(Complex) {
+				d = compareStep(a, b, a.complexData[name], b.complexData[name], step, byIndex, tol)
+			} else {
+				d = compareStep(a, b, a.data[name], b.data[name], step, byIndex, tol)
+			}
+			diff.Steps = append(diff.Steps, d)
+		}
+		report.Traces = append(report.Traces, diff)
+	}
+	return report, nil
+}
+
+func compareStep[T float64 | complex128](a, b *SimData, dataA, dataB []T, step int, byIndex bool, tol Tolerance) StepDiff {
+	startA, endA := a.stepBounds(step)
+	startB, endB := b.stepBounds(step)
+	ya, yb := dataA[startA:endA], dataB[startB:endB]
+	xa, xb := a.GetXAxis(step), b.GetXAxis(step)
+	lo, hi := xRange(xb)
+
+	d := StepDiff{Step: step}
+	for i := range ya {
+		var ref, got T
+		if byIndex {
+			if i >= len(yb) {
+				break
+			}
+			ref, got = ya[i], yb[i]
+		} else {
+			if xa[i] < lo || xa[i] > hi {
+				continue
+			}
+			ref, got = ya[i], interpLinear(xb, yb, xa[i])
+		}
+		abs, mag := absDiff(ref, got)
+		rel := 0.0
+		if mag > 0 {
+			rel = abs / mag
+		} else if abs > 0 {
+			rel = math.Inf(1)
+		}
+		x := float64(i)
+		if !byIndex {
+			x = xa[i]
+		}
+		if d.Points == 0 || abs > d.MaxAbs {
+			d.MaxAbs, d.MaxAbsAt = abs, x
+		}
+		if d.Points == 0 || rel > d.MaxRel {
+			d.MaxRel, d.MaxRelAt = rel, x
+		}
+		if abs > tol.Abs+tol.Rel*mag || math.IsNaN(abs) {
+			d.Failures++
+			d.addWorst(Deviation{X: x, Abs: abs, Rel: rel})
+		}
+		d.Points++
+	}
+	return d
+}
+
+// addWorst inserts dev into the sorted list of worst deviations, keeping at most MaxWorstDeviations.
+func (d *StepDiff) addWorst(dev Deviation) {
+	i := sort.Search(len(d.Worst), func(i int) bool { return !(d.Worst[i].Abs >= dev.Abs) })
+	if i >= MaxWorstDeviations {
+		return
+	}
+	d.Worst = append(d.Worst, Deviation{})
+	copy(d.Worst[i+1:], d.Worst[i:])
+	d.Worst[i] = dev
+	if len(d.Worst) > MaxWorstDeviations {
+		d.Worst = d.Worst[:MaxWorstDeviations]
+	}
+}
+
+// absDiff returns |a - b| and |a|.
+func absDiff[T float64 | complex128](a, b T) (float64, float64) {
+	switch v := any(a).(type) {
+	case float64:
+		w := any(b).(float64)
+		return math.Abs(v - w), math.Abs(v)
+	case complex128:
+		w := any(b).(complex128)
+		return cmplx.Abs(v - w), cmplx.Abs(v)
+	}
+	return 0, 0
+}
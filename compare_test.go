@@ -0,0 +1,115 @@
+package ltspice
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareIdentical(t *testing.T) {
+	a, err := Parse("testdata/simulations/trans/stepped2/TRAN-STEP.raw")
+	require.NoError(t, err)
+	b, err := Parse("testdata/simulations/trans/stepped2/TRAN-STEP.raw")
+	require.NoError(t, err)
+
+	report, err := Compare(a, b, CompareOptions{})
+	require.NoError(t, err)
+	assert.True(t, report.Passed())
+	assert.Empty(t, report.Added)
+	assert.Empty(t, report.Removed)
+	require.Len(t, report.Traces, 5)
+	for _, tr := range report.Traces {
+		require.Len(t, tr.Steps, 4)
+		for _, s := range tr.Steps {
+			assert.Zero(t, s.MaxAbs)
+			assert.Equal(t, len(a.GetXAxis(s.Step)), s.Points)
+		}
+	}
+}
+
+func TestCompareDeviation(t *testing.T) {
+	a, err := Parse("testdata/simulations/trans/stepped2/TRAN-STEP.raw")
+	require.NoError(t, err)
+	b, err := Parse("testdata/simulations/trans/stepped2/TRAN-STEP.raw")
+	require.NoError(t, err)
+
+	// offset a single point of V(out) in step 1
+	start, _ := b.stepBounds(1)
+	idx := start + 10
+	b.data["V(out)"] = append([]float64(nil), b.data["V(out)"]...)
+	b.data["V(out)"][idx] += 0.5
+
+	report, err := Compare(a, b, CompareOptions{
+		Tolerance: Tolerance{Abs: 1e-3},
+		Traces:    map[string]*Tolerance{"V(out)": nil, "V(in)": {Abs: 1}},
+	})
+	require.NoError(t, err)
+	assert.False(t, report.Passed())
+	require.Len(t, report.Traces, 2)
+
+	vin, vout := report.Traces[0], report.Traces[1]
+	assert.Equal(t, "V(in)", vin.Name)
+	assert.False(t, vin.Exceeded())
+	assert.Equal(t, "V(out)", vout.Name)
+	assert.True(t, vout.Exceeded())
+	assert.Zero(t, vout.Steps[0].Failures)
+	assert.Equal(t, 1, vout.Steps[1].Failures)
+	assert.InDelta(t, 0.5, vout.Steps[1].MaxAbs, 1e-6)
+	assert.Equal(t, b.data["time"][idx], vout.Steps[1].MaxAbsAt)
+	require.Len(t, vout.Steps[1].Worst, 1)
+	assert.Equal(t, vout.Steps[1].MaxAbsAt, vout.Steps[1].Worst[0].X)
+
+	report, err = Compare(a, b, CompareOptions{Tolerance: Tolerance{Abs: 1}})
+	require.NoError(t, err)
+	assert.True(t, report.Passed())
+}
+
+func TestCompareVariables(t *testing.T) {
+	a, err := Parse("testdata/simulations/trans/stepped/tran-stepped.raw")
+	require.NoError(t, err)
+	b, err := Parse("testdata/simulations/trans/stepped2/TRAN-STEP.raw")
+	require.NoError(t, err)
+
+	report, err := Compare(a, b, CompareOptions{Tolerance: Tolerance{Abs: 1e9}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"V(in)", "V(out)", "I(C1)", "I(Vin)"}, report.Added)
+	assert.Equal(t, []string{"V(n001)", "V(n002)", "I(R2)", "I(V1)"}, report.Removed)
+	require.Len(t, report.Traces, 1)
+	assert.Equal(t, "I(R1)", report.Traces[0].Name)
+	assert.Len(t, report.Traces[0].Steps, 2)
+	assert.False(t, report.Passed(), "step count differs")
+
+	_, err = Compare(a, b, CompareOptions{Traces: map[string]*Tolerance{"V(out)": nil}})
+	assert.True(t, errors.Is(err, ErrTraceDoesNotExist))
+
+	ac, err := Parse("testdata/simulations/ac/low-pass/low-pass-filter.raw")
+	require.NoError(t, err)
+	_, err = Compare(a, ac, CompareOptions{})
+	assert.True(t, errors.Is(err, ErrInvalidSimulationType))
+}
+
+func TestCompareOperatingPoint(t *testing.T) {
+	a, err := Parse("testdata/simulations/op/iter/iter.raw")
+	require.NoError(t, err)
+	report, err := Compare(a, a, CompareOptions{})
+	require.NoError(t, err)
+	assert.True(t, report.Passed())
+	require.NotEmpty(t, report.Traces)
+	assert.Len(t, report.Traces[0].Steps, a.GetSteps())
+	assert.Equal(t, 1, report.Traces[0].Steps[0].Points)
+}
+
+func TestStepDiffWorst(t *testing.T) {
+	var d StepDiff
+	for i, abs := range []float64{1, 7, 3, 9, 2, 8, 5} {
+		d.addWorst(Deviation{X: float64(i), Abs: abs})
+	}
+	var got []float64
+	for _, w := range d.Worst {
+		got = append(got, w.Abs)
+	}
+	assert.Equal(t, []float64{9, 8, 7, 5, 3}, got)
+	assert.Equal(t, 3.0, d.Worst[0].X)
+}
@@ -0,0 +1,154 @@
+package ltspice
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+const (
+	// defaultHarmonics matches the default number of harmonics of LTSpice's .four command.
+	defaultHarmonics = 9
+	// defaultToneWidth covers the main lobe of all windows of FFT.
+	defaultToneWidth = 5
+)
+
+// DistortionOptions configures Distortion.
+type DistortionOptions struct {
+	// Fundamental is the frequency of the fundamental in Hz. If it is zero, the largest tone of the spectrum
+	// is used.
+	Fundamental float64
+	// Harmonics is the highest harmonic included in the THD, defaults to 9 like .four. Harmonics above the
+	// Nyquist frequency are ignored.
+	Harmonics int
+	// Width is the number of bins on each side of a tone which belong to it, defaults to 5.
+	Width int
+}
+
+// Distortion holds the harmonic distortion and noise metrics of a spectrum.
+type Distortion struct {
+	// Fundamental is the frequency of the fundamental in Hz and Amplitude its amplitude.
+	Fundamental float64
+	Amplitude   float64
+	// Harmonics holds the amplitudes of the harmonics, starting with the 2nd.
+	Harmonics []float64
+	// THD and THDN are the total harmonic distortion without and with noise as ratios to the fundamental,
+	// multiply by 100 for percent.
+	THD  float64
+	THDN float64
+	// SNR, SINAD and SFDR are in dB.
+	SNR   float64
+	SINAD float64
+	SFDR  float64
+	// ENOB is the effective number of bits calculated from the SINAD.
+	ENOB float64
+}
+
+// Distortion computes the harmonic distortion metrics of a trace of a spectrum returned by FFT. For stepped
+// spectra a step index selects the step, the first step is used if none is given.
+//
+// The power of a tone is the sum over Width bins on each side of its peak. Harmonics are located at integer
+// multiples of the fundamental bin, the noise is the power of all remaining bins except DC.
+//
+// Example usage:
+//
+//	spectrum, err := simData.FFT(ltspice.FFTOptions{Window: ltspice.BlackmanHarrisWindow}, "V(out)")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	d, err := spectrum.Distortion("V(out)", ltspice.DistortionOptions{Fundamental: 1e3})
+//	fmt.Printf("THD: %.4f%%, SINAD: %.1f dB\n", 100*d.THD, d.SINAD)
+func (sim *SimData) Distortion(name string, opts DistortionOptions, step ...int) (*Distortion, error) {
+	if !sim.Meta.Flags.hasFlag(Complex) || sim.GetType() != ACAnalysis {
+		return nil, fmt.Errorf("%w: distortion requires a spectrum, got %s", ErrInvalidSimulationType, sim.GetType())
+	}
+	if opts.Harmonics < 0 || opts.Width < 0 || opts.Fundamental < 0 {
+		return nil, errors.New("distortion: fundamental, harmonics and width must not be negative")
+	}
+	if opts.Harmonics == 0 {
+		opts.Harmonics = defaultHarmonics
+	}
+	if opts.Width == 0 {
+		opts.Width = defaultToneWidth
+	}
+	trace, err := GetTrace[complex128](sim, name)
+	if err != nil {
+		return nil, err
+	}
+	bins := trace.GetSignal(step...)
+	f := sim.GetXAxis(step...)
+	if len(bins) < 2 || len(f) != len(bins) {
+		return nil, errors.New("distortion: spectrum has less than two bins")
+	}
+	df := f[1] - f[0]
+
+	// used marks the bins which belong to DC, the fundamental or a harmonic
+	used := make([]bool, len(bins))
+	power := func(center int) (float64, float64) {
+		var p, peak float64
+		for k := max(0, center-opts.Width); k <= min(len(bins)-1, center+opts.Width); k++ {
+			if used[k] {
+				continue
+			}
+			used[k] = true
+			a := cmplx.Abs(bins[k])
+			p += a * a
+			peak = math.Max(peak, a)
+		}
+		return p, peak
+	}
+	power(0)
+
+	var fundamental int
+	if opts.Fundamental > 0 {
+		fundamental = peakBin(bins, used, int(math.Round(opts.Fundamental/df)), opts.Width)
+	} else {
+		fundamental = peakBin(bins, used, len(bins)/2, len(bins))
+	}
+	if fundamental <= 0 || fundamental >= len(bins) || used[fundamental] {
+		return nil, fmt.Errorf("distortion: no fundamental found in %s", name)
+	}
+
+	d := &Distortion{Fundamental: f[fundamental]}
+	signal, amplitude := power(fundamental)
+	d.Amplitude = amplitude
+	var harmonics float64
+	for h := 2; h <= opts.Harmonics && h*fundamental < len(bins); h++ {
+		p, a := power(h * fundamental)
+		harmonics += p
+		d.Harmonics = append(d.Harmonics, a)
+	}
+	var noise, spur float64
+	for k, b := range bins {
+		a := cmplx.Abs(b)
+		if !used[k] {
+			noise += a * a
+		}
+	}
+	// the largest spur is the largest bin which is not part of DC or the fundamental
+	for k, b := range bins {
+		if k > opts.Width && (k < fundamental-opts.Width || k > fundamental+opts.Width) {
+			spur = math.Max(spur, cmplx.Abs(b))
+		}
+	}
+
+	d.THD = math.Sqrt(harmonics / signal)
+	d.THDN = math.Sqrt((harmonics + noise) / signal)
+	d.SNR = 10 * math.Log10(signal/noise)
+	d.SINAD = 10 * math.Log10(signal/(harmonics+noise))
+	d.SFDR = 20 * math.Log10(amplitude/spur)
+	d.ENOB = (d.SINAD - 1.76) / 6.02
+	return d, nil
+}
+
+// peakBin returns the index of the largest unused bin within width bins of center.
+func peakBin(bins []complex128, used []bool, center, width int) int {
+	peak, best := -1, 0.0
+	for k := max(0, center-width); k <= min(len(bins)-1, center+width); k++ {
+		if a := cmplx.Abs(bins[k]); !used[k] && a > best {
+			peak, best = k, a
+		}
+	}
+	return peak
+}
@@ -0,0 +1,80 @@
+package ltspice
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// distortedSim returns two steps of a 1 kHz sine with 1% 2nd and 0.5% 3rd harmonic sampled uniformly over
+// 20 periods. White noise with the given standard deviation is added to the second step.
+func distortedSim(t *testing.T, sigma float64) *SimData {
+	rng := rand.New(rand.NewSource(4))
+	const n = 4096
+	var x, y []float64
+	for step := 0; step < 2; step++ {
+		for i := 0; i <= n; i++ {
+			tm := 20e-3 * float64(i) / n
+			v := math.Sin(2*math.Pi*1e3*tm) + 0.01*math.Sin(2*math.Pi*2e3*tm) + 0.005*math.Sin(2*math.Pi*3e3*tm)
+			if step == 1 {
+				v += sigma * rng.NormFloat64()
+			}
+			x = append(x, tm)
+			y = append(y, v)
+		}
+	}
+	sim, err := newTableSim(TransientAnalysis, "distortion", []string{"time", "V(out)"}, [][]float64{x, y}, nil)
+	require.NoError(t, err)
+	return sim
+}
+
+func TestDistortion(t *testing.T) {
+	sim := distortedSim(t, 1e-3)
+	spectrum, err := sim.FFT(FFTOptions{Window: BlackmanHarrisWindow, Points: 4096})
+	require.NoError(t, err)
+
+	d, err := spectrum.Distortion("V(out)", DistortionOptions{})
+	require.NoError(t, err)
+	assert.InDelta(t, 1e3, d.Fundamental, 1e-9)
+	assert.InDelta(t, 1, d.Amplitude, 1e-3)
+	require.Len(t, d.Harmonics, 8)
+	assert.InDelta(t, 0.01, d.Harmonics[0], 1e-5)
+	assert.InDelta(t, 0.005, d.Harmonics[1], 1e-5)
+	assert.InDelta(t, 0, d.Harmonics[2], 1e-5)
+	wantTHD := math.Sqrt(0.01*0.01 + 0.005*0.005)
+	assert.InDelta(t, wantTHD, d.THD, 1e-5)
+	assert.InDelta(t, wantTHD, d.THDN, 1e-5)
+	assert.InDelta(t, 40, d.SFDR, 1e-2)
+	// the first step is free of noise
+	assert.Greater(t, d.SNR, 150.0)
+	assert.InDelta(t, 20*math.Log10(1/wantTHD), d.SINAD, 1e-2)
+
+	// 0.5 / 1e-6 is 57 dB
+	d, err = spectrum.Distortion("V(out)", DistortionOptions{Fundamental: 1e3, Harmonics: 3}, 1)
+	require.NoError(t, err)
+	assert.Len(t, d.Harmonics, 2)
+	assert.InDelta(t, wantTHD, d.THD, 1e-4)
+	assert.Greater(t, d.THDN, d.THD)
+	assert.InDelta(t, 57, d.SNR, 0.5)
+	assert.Less(t, d.SINAD, d.SNR)
+	assert.InDelta(t, (d.SINAD-1.76)/6.02, d.ENOB, 1e-12)
+}
+
+func TestDistortionErrors(t *testing.T) {
+	sim := distortedSim(t, 0)
+	_, err := sim.Distortion("V(out)", DistortionOptions{})
+	assert.ErrorIs(t, err, ErrInvalidSimulationType)
+
+	spectrum, err := sim.FFT(FFTOptions{Window: HannWindow})
+	require.NoError(t, err)
+	_, err = spectrum.Distortion("V(missing)", DistortionOptions{})
+	assert.ErrorIs(t, err, ErrTraceDoesNotExist)
+	_, err = spectrum.Distortion("V(out)", DistortionOptions{Width: -1})
+	assert.Error(t, err)
+	// there is no tone near DC
+	_, err = spectrum.Distortion("V(out)", DistortionOptions{Fundamental: 1, Width: 2})
+	assert.Error(t, err)
+}
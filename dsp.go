@@ -0,0 +1,583 @@
+package ltspice
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// FilterType selects the pass band of a filter.
+type FilterType int
+
+const (
+	LowPass FilterType = iota
+	HighPass
+)
+
+func (f FilterType) String() string {
+	return [...]string{"low-pass", "high-pass"}[f]
+}
+
+// FilterDesign selects the prototype of a filter.
+type FilterDesign int
+
+const (
+	// ButterworthFilter is an IIR filter with a maximally flat pass band.
+	ButterworthFilter FilterDesign = iota
+	// ChebyshevFilter is a type I Chebyshev IIR filter, steeper than a Butterworth filter of the same order
+	// at the cost of ripple in the pass band.
+	ChebyshevFilter
+	// FIRFilter is a windowed sinc FIR filter.
+	FIRFilter
+	// MovingAverageFilter averages a sliding window of samples.
+	MovingAverageFilter
+)
+
+func (d FilterDesign) String() string {
+	return [...]string{"butterworth", "chebyshev", "fir", "moving-average"}[d]
+}
+
+const (
+	defaultIIROrder = 4
+	defaultFIROrder = 64
+	// defaultChebyshevRipple is the pass band ripple of Chebyshev filters in dB.
+	defaultChebyshevRipple = 1
+	// movingAverageCutoff is the -3 dB frequency of a moving average of n samples times n / fs.
+	movingAverageCutoff = 0.443
+)
+
+// FilterOptions configures Filter.
+type FilterOptions struct {
+	Type   FilterType
+	Design FilterDesign
+	// Cutoff is the cutoff frequency in Hz, the -3 dB frequency of Butterworth filters and moving averages,
+	// the -6 dB frequency of FIR filters and the edge of the ripple band of Chebyshev filters.
+	Cutoff float64
+	// Order is the order of IIR filters and the number of taps minus one of FIR filters, defaults to 4
+	// and 64. High-pass FIR filters require an even order. The length of moving averages is derived
+	// from Cutoff.
+	Order int
+	// Ripple is the pass band ripple of Chebyshev filters in dB, defaults to 1.
+	Ripple float64
+	// Window is the window of FIR filters. HannWindow or KaiserWindow give a much better stop band
+	// attenuation than the rectangular window.
+	Window Window
+	// Beta is the shape parameter of the Kaiser window, defaults to 12.
+	Beta float64
+	// ZeroPhase filters the trace forward and backward, which cancels the delay of the filter and
+	// squares its magnitude response.
+	ZeroPhase bool
+}
+
+// Uniform returns a copy of the trace resampled on a uniform grid, see SimData.Resample for the options.
+// Filter, Decimate, Detrend, RemoveDC and Hilbert resample non-uniform traces linearly with the number of
+// points of each step, Uniform gives control over the grid and the interpolation.
+func Uniform(t *Trace[float64], opts ResampleOptions) (*Trace[float64], error) {
+	xs, ys, err := traceSteps(t)
+	if err != nil {
+		return nil, err
+	}
+	for i := range xs {
+		grid, err := resampleGrid(xs[i], opts)
+		if err != nil {
+			return nil, fmt.Errorf("resample %s: %w", t.Name, err)
+		}
+		xs[i], ys[i] = grid, interpolateReal(xs[i], ys[i], grid, opts)
+	}
+	return newDerivedTrace(t.Name, xs, ys), nil
+}
+
+// Filter applies a digital filter to a trace of a transient analysis and returns the filtered trace. Each
+// step of a stepped simulation is filtered on its own. The filter starts in the steady state of the first
+// sample, so a trace with a DC offset does not cause a start-up transient.
+//
+// Example usage:
+//
+//	trace, err := ltspice.GetTrace[float64](simData, "V(out)")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	filtered, err := ltspice.Filter(trace, ltspice.FilterOptions{Design: ltspice.ButterworthFilter, Cutoff: 10e3, Order: 6})
+func Filter(t *Trace[float64], opts FilterOptions) (*Trace[float64], error) {
+	xs, ys, err := uniformSteps(t)
+	if err != nil {
+		return nil, err
+	}
+	for i := range xs {
+		fs := 1 / (xs[i][1] - xs[i][0])
+		f, err := designFilter(opts, fs)
+		if err != nil {
+			return nil, fmt.Errorf("filter %s: %w", t.Name, err)
+		}
+		ys[i] = f.apply(ys[i], opts.ZeroPhase)
+	}
+	return newDerivedTrace(t.Name, xs, ys), nil
+}
+
+// Decimate reduces the sample rate of a trace by an integer factor. The trace is low-pass filtered with
+// a zero phase 7th order Chebyshev filter at 80% of the new Nyquist frequency to prevent aliasing, then
+// every factor-th sample is kept.
+func Decimate(t *Trace[float64], factor int) (*Trace[float64], error) {
+	if factor < 1 {
+		return nil, fmt.Errorf("decimate %s: factor must be positive", t.Name)
+	}
+	xs, ys, err := uniformSteps(t)
+	if err != nil {
+		return nil, err
+	}
+	for i := range xs {
+		if factor > 1 {
+			fs := 1 / (xs[i][1] - xs[i][0])
+			f, err := designFilter(FilterOptions{Design: ChebyshevFilter, Order: 7, Ripple: 0.05, Cutoff: 0.8 * fs / 2 / float64(factor)}, fs)
+			if err != nil {
+				return nil, fmt.Errorf("decimate %s: %w", t.Name, err)
+			}
+			ys[i] = f.apply(ys[i], true)
+		}
+		x, y := make([]float64, 0, len(xs[i])/factor+1), make([]float64, 0, len(ys[i])/factor+1)
+		for j := 0; j < len(xs[i]); j += factor {
+			x, y = append(x, xs[i][j]), append(y, ys[i][j])
+		}
+		xs[i], ys[i] = x, y
+	}
+	return newDerivedTrace(t.Name, xs, ys), nil
+}
+
+// Detrend removes the least squares straight line from each step of a trace.
+func Detrend(t *Trace[float64]) (*Trace[float64], error) {
+	xs, ys, err := uniformSteps(t)
+	if err != nil {
+		return nil, err
+	}
+	for i := range xs {
+		x, y := xs[i], ys[i]
+		var mx, my float64
+		for j := range x {
+			mx += x[j]
+			my += y[j]
+		}
+		mx, my = mx/float64(len(x)), my/float64(len(x))
+		var sxy, sxx float64
+		for j := range x {
+			sxy += (x[j] - mx) * (y[j] - my)
+			sxx += (x[j] - mx) * (x[j] - mx)
+		}
+		slope := sxy / sxx
+		out := make([]float64, len(y))
+		for j := range y {
+			out[j] = y[j] - my - slope*(x[j]-mx)
+		}
+		ys[i] = out
+	}
+	return newDerivedTrace(t.Name, xs, ys), nil
+}
+
+// RemoveDC subtracts the mean value from each step of a trace.
+func RemoveDC(t *Trace[float64]) (*Trace[float64], error) {
+	xs, ys, err := uniformSteps(t)
+	if err != nil {
+		return nil, err
+	}
+	for i, y := range ys {
+		var mean float64
+		for _, v := range y {
+			mean += v
+		}
+		mean /= float64(len(y))
+		out := make([]float64, len(y))
+		for j, v := range y {
+			out[j] = v - mean
+		}
+		ys[i] = out
+	}
+	return newDerivedTrace(t.Name, xs, ys), nil
+}
+
+// Hilbert returns the analytic signal of a trace, whose real part is the trace and whose imaginary part
+// is its Hilbert transform. The trace is zero padded, so the transform is not circular.
+func Hilbert(t *Trace[float64]) (*Trace[complex128], error) {
+	xs, ys, err := uniformSteps(t)
+	if err != nil {
+		return nil, err
+	}
+	out := &Trace[complex128]{Name: t.Name, s: &steps{count: len(xs)}}
+	for i := range xs {
+		out.s.offsets = append(out.s.offsets, len(out.x))
+		out.x = append(out.x, xs[i]...)
+		out.Data = append(out.Data, analyticSignal(ys[i])...)
+	}
+	return out, nil
+}
+
+// Envelope returns the envelope of a trace, the magnitude of its analytic signal. It recovers the amplitude
+// of modulated carriers and of decaying oscillations.
+func Envelope(t *Trace[float64]) (*Trace[float64], error) {
+	a, err := Hilbert(t)
+	if err != nil {
+		return nil, err
+	}
+	env := &Trace[float64]{Name: t.Name, s: a.s, x: a.x, Data: make([]float64, len(a.Data))}
+	for i, c := range a.Data {
+		env.Data[i] = cmplx.Abs(c)
+	}
+	return env, nil
+}
+
+// Derivative returns the numerical derivative of a trace with respect to its x-axis, like d() in the
+// waveform viewer of LTSpice. The trace is differentiated on its own x-axis using central differences,
+// which are exact for parabolas on non-uniform grids, and one-sided differences at the end points.
+// The name of the result is "d(name)".
+func Derivative(t *Trace[float64]) (*Trace[float64], error) {
+	xs, ys, err := traceSteps(t)
+	if err != nil {
+		return nil, err
+	}
+	for i := range xs {
+		x, y := xs[i], ys[i]
+		if len(x) < 2 {
+			return nil, fmt.Errorf("derivative of %s: a step holds less than two points", t.Name)
+		}
+		n := len(x)
+		d := make([]float64, n)
+		slope := func(a, b int) float64 {
+			if x[b] == x[a] {
+				return math.NaN()
+			}
+			return (y[b] - y[a]) / (x[b] - x[a])
+		}
+		d[0], d[n-1] = slope(0, 1), slope(n-2, n-1)
+		for j := 1; j < n-1; j++ {
+			h0, h1 := x[j]-x[j-1], x[j+1]-x[j]
+			switch {
+			case h0 == 0:
+				// LTSpice repeats time points at discontinuities
+				d[j] = slope(j, j+1)
+			case h1 == 0:
+				d[j] = slope(j-1, j)
+			default:
+				d[j] = (-h1/(h0*(h0+h1)))*y[j-1] + ((h1-h0)/(h0*h1))*y[j] + (h0/(h1*(h0+h1)))*y[j+1]
+			}
+		}
+		ys[i] = d
+	}
+	return newDerivedTrace("d("+t.Name+")", xs, ys), nil
+}
+
+// Integral returns the running integral of a trace over its x-axis, like idt() in the waveform viewer of
+// LTSpice. Each step is integrated on its own x-axis with the trapezoidal rule, starting at zero. The name
+// of the result is "idt(name)".
+func Integral(t *Trace[float64]) (*Trace[float64], error) {
+	xs, ys, err := traceSteps(t)
+	if err != nil {
+		return nil, err
+	}
+	for i := range xs {
+		x, y := xs[i], ys[i]
+		out := make([]float64, len(y))
+		for j := 1; j < len(y); j++ {
+			out[j] = out[j-1] + (y[j]+y[j-1])/2*(x[j]-x[j-1])
+		}
+		ys[i] = out
+	}
+	return newDerivedTrace("idt("+t.Name+")", xs, ys), nil
+}
+
+// traceSteps returns the x-axis and the signal of each step of a trace.
+func traceSteps(t *Trace[float64]) ([][]float64, [][]float64, error) {
+	if t.s == nil || t.sim == nil && len(t.x) != len(t.Data) {
+		return nil, nil, fmt.Errorf("%s: the trace has no x-axis", t.Name)
+	}
+	count := max(t.s.count, 1)
+	xs, ys := make([][]float64, count), make([][]float64, count)
+	for i := range xs {
+		xs[i], ys[i] = t.GetXAxis(i), t.GetSignal(i)
+	}
+	return xs, ys, nil
+}
+
+// uniformSteps returns the steps of a trace on a uniform x-axis. Steps which are not uniformly sampled are
+// resampled linearly with the same number of points.
+func uniformSteps(t *Trace[float64]) ([][]float64, [][]float64, error) {
+	xs, ys, err := traceSteps(t)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, x := range xs {
+		if len(x) < 2 {
+			return nil, nil, fmt.Errorf("%s: a step holds less than two points", t.Name)
+		}
+		if x[len(x)-1] <= x[0] {
+			return nil, nil, fmt.Errorf("%s: the x-axis is not increasing", t.Name)
+		}
+		if !isUniform(x) {
+			grid := linspace(x[0], x[len(x)-1], len(x))
+			xs[i], ys[i] = grid, interpolateReal(x, ys[i], grid, ResampleOptions{})
+		}
+	}
+	return xs, ys, nil
+}
+
+func isUniform(x []float64) bool {
+	h := (x[len(x)-1] - x[0]) / float64(len(x)-1)
+	for i := 1; i < len(x); i++ {
+		if math.Abs(x[i]-x[i-1]-h) > 1e-6*h {
+			return false
+		}
+	}
+	return true
+}
+
+// newDerivedTrace returns a trace holding the given steps.
+func newDerivedTrace(name string, xs, ys [][]float64) *Trace[float64] {
+	t := &Trace[float64]{Name: name, s: &steps{count: len(xs)}}
+	for i := range xs {
+		t.s.offsets = append(t.s.offsets, len(t.x))
+		t.x = append(t.x, xs[i]...)
+		t.Data = append(t.Data, ys[i]...)
+	}
+	return t
+}
+
+// digitalFilter is a cascade of second order sections for IIR filters or the taps of an FIR filter.
+type digitalFilter struct {
+	sections []biquad
+	taps     []float64
+}
+
+// biquad is a second order section b0 + b1 z^-1 + b2 z^-2 / 1 + a1 z^-1 + a2 z^-2.
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+}
+
+func designFilter(opts FilterOptions, fs float64) (*digitalFilter, error) {
+	if opts.Cutoff <= 0 || opts.Cutoff >= fs/2 {
+		return nil, fmt.Errorf("cutoff %g Hz must be between 0 and the Nyquist frequency %g Hz", opts.Cutoff, fs/2)
+	}
+	if opts.Order < 0 {
+		return nil, errors.New("order must not be negative")
+	}
+	highPass := opts.Type == HighPass
+
+	switch opts.Design {
+	case MovingAverageFilter:
+		n := max(1, int(math.Round(movingAverageCutoff*fs/opts.Cutoff)))
+		taps := make([]float64, n)
+		for i := range taps {
+			taps[i] = 1 / float64(n)
+		}
+		if highPass {
+			// the complement of the average, delayed to its center, needs an odd length
+			if n%2 == 0 {
+				n++
+				taps = make([]float64, n)
+				for i := range taps {
+					taps[i] = 1 / float64(n)
+				}
+			}
+			invert(taps)
+		}
+		return &digitalFilter{taps: taps}, nil
+
+	case FIRFilter:
+		order := opts.Order
+		if order == 0 {
+			order = defaultFIROrder
+		}
+		if highPass && order%2 != 0 {
+			return nil, fmt.Errorf("high-pass FIR filters require an even order, got %d", order)
+		}
+		window := append(windowCoefficients(opts.Window, order, opts.Beta), 0)
+		window[order] = window[0]
+		fc := opts.Cutoff / fs
+		taps := make([]float64, order+1)
+		var sum float64
+		for i := range taps {
+			taps[i] = 2 * fc * sinc(2*fc*(float64(i)-float64(order)/2)) * window[i]
+			sum += taps[i]
+		}
+		for i := range taps {
+			taps[i] /= sum
+		}
+		if highPass {
+			invert(taps)
+		}
+		return &digitalFilter{taps: taps}, nil
+
+	case ButterworthFilter, ChebyshevFilter:
+		order := opts.Order
+		if order == 0 {
+			order = defaultIIROrder
+		}
+		// analog prototype poles with a cutoff of 1 rad/s
+		poles := make([]complex128, order)
+		gain := 1.0
+		for k := range poles {
+			theta := math.Pi * float64(2*k+1) / float64(2*order)
+			poles[k] = complex(-math.Sin(theta), math.Cos(theta))
+		}
+		if opts.Design == ChebyshevFilter {
+			ripple := opts.Ripple
+			if ripple == 0 {
+				ripple = defaultChebyshevRipple
+			}
+			eps := math.Sqrt(math.Pow(10, ripple/10) - 1)
+			mu := math.Asinh(1/eps) / float64(order)
+			for k := range poles {
+				poles[k] = complex(real(poles[k])*math.Sinh(mu), imag(poles[k])*math.Cosh(mu))
+			}
+			if order%2 == 0 {
+				// the pass band lies between the ripple and 0 dB
+				gain = 1 / math.Sqrt(1+eps*eps)
+			}
+		}
+		return &digitalFilter{sections: bilinearSections(poles, opts.Cutoff, fs, highPass, gain)}, nil
+	}
+	return nil, fmt.Errorf("unknown filter design %d", opts.Design)
+}
+
+// invert turns the taps of a linear phase low-pass filter into the complementary high-pass filter.
+func invert(taps []float64) {
+	for i := range taps {
+		taps[i] = -taps[i]
+	}
+	taps[len(taps)/2]++
+}
+
+// bilinearSections maps the normalized analog low-pass poles to second order sections of a digital filter
+// with the cutoff fc using the bilinear transform with prewarping. The gain of the pass band is set to gain.
+func bilinearSections(poles []complex128, fc, fs float64, highPass bool, gain float64) []biquad {
+	wc := 2 * fs * math.Tan(math.Pi*fc/fs)
+	digital := func(p complex128) complex128 {
+		s := complex(wc, 0) * p
+		if highPass {
+			s = complex(wc, 0) / p
+		}
+		return (complex(2*fs, 0) + s) / (complex(2*fs, 0) - s)
+	}
+	// zeros at z = -1 for low-pass and z = 1 for high-pass filters, the gain is normalized at z = 1 or z = -1
+	zero, ref := -1.0, 1.0
+	if highPass {
+		zero, ref = 1, -1
+	}
+
+	var sections []biquad
+	for _, p := range poles {
+		if imag(p) < -1e-12 {
+			continue // the conjugate of a pole with a positive imaginary part
+		}
+		z := digital(p)
+		var s biquad
+		if math.Abs(imag(p)) <= 1e-12 {
+			s = biquad{b0: 1, b1: -zero, a1: -real(z)}
+		} else {
+			s = biquad{b0: 1, b1: -2 * zero, b2: zero * zero, a1: -2 * real(z), a2: real(z)*real(z) + imag(z)*imag(z)}
+		}
+		k := (1 + s.a1*ref + s.a2) / (s.b0 + s.b1*ref + s.b2)
+		s.b0, s.b1, s.b2 = k*s.b0, k*s.b1, k*s.b2
+		sections = append(sections, s)
+	}
+	sections[0].b0 *= gain
+	sections[0].b1 *= gain
+	sections[0].b2 *= gain
+	return sections
+}
+
+// apply filters the samples, forward and backward if zeroPhase is set. For zero phase filtering the samples
+// are extended by their point reflection at both ends, which keeps the slope of the trace, so the filter
+// has settled when it reaches the samples.
+func (f *digitalFilter) apply(y []float64, zeroPhase bool) []float64 {
+	if !zeroPhase {
+		return f.filter(y)
+	}
+	pad := min(len(y)-1, f.settling())
+	ext := make([]float64, 0, len(y)+2*pad)
+	for i := pad; i > 0; i-- {
+		ext = append(ext, 2*y[0]-y[i])
+	}
+	ext = append(ext, y...)
+	for i := 1; i <= pad; i++ {
+		ext = append(ext, 2*y[len(y)-1]-y[len(y)-1-i])
+	}
+	out := f.filter(ext)
+	reverse(out)
+	out = f.filter(out)
+	reverse(out)
+	return out[pad : pad+len(y)]
+}
+
+// settling returns the number of samples it takes the impulse response to decay to a millionth.
+func (f *digitalFilter) settling() int {
+	n := 3 * len(f.taps)
+	for _, s := range f.sections {
+		// the magnitude of the poles is sqrt(a2) for pairs and |a1| for single poles
+		r := math.Abs(s.a1)
+		if s.a2 != 0 {
+			r = math.Sqrt(s.a2)
+		}
+		if r > 0 && r < 1 {
+			n = max(n, int(math.Ceil(math.Log(1e-6)/math.Log(r))))
+		}
+	}
+	return n
+}
+
+func (f *digitalFilter) filter(y []float64) []float64 {
+	if f.taps != nil {
+		out := make([]float64, len(y))
+		for i := range y {
+			for k, h := range f.taps {
+				// samples before the start hold the first value
+				out[i] += h * y[max(i-k, 0)]
+			}
+		}
+		return out
+	}
+	out := append([]float64(nil), y...)
+	for _, s := range f.sections {
+		// transposed direct form II, starting in the steady state of the first sample
+		x0 := out[0]
+		y0 := x0 * (s.b0 + s.b1 + s.b2) / (1 + s.a1 + s.a2)
+		z1, z2 := y0-s.b0*x0, s.b2*x0-s.a2*y0
+		for i, x := range out {
+			v := s.b0*x + z1
+			z1 = s.b1*x - s.a1*v + z2
+			z2 = s.b2*x - s.a2*v
+			out[i] = v
+		}
+	}
+	return out
+}
+
+func reverse(y []float64) {
+	for i, j := 0, len(y)-1; i < j; i, j = i+1, j-1 {
+		y[i], y[j] = y[j], y[i]
+	}
+}
+
+// analyticSignal returns the analytic signal of y, computed with an FFT of the zero padded samples.
+func analyticSignal(y []float64) []complex128 {
+	n := nextPowerOfTwo(2 * len(y))
+	a := make([]complex128, n)
+	for i, v := range y {
+		a[i] = complex(v, 0)
+	}
+	fftRadix2(a)
+	// keep DC and Nyquist, double the positive and drop the negative frequencies
+	for k := 1; k < n/2; k++ {
+		a[k] *= 2
+	}
+	for k := n/2 + 1; k < n; k++ {
+		a[k] = 0
+	}
+	// inverse transform via conjugation
+	for i := range a {
+		a[i] = cmplx.Conj(a[i])
+	}
+	fftRadix2(a)
+	out := make([]complex128, len(y))
+	for i := range out {
+		out[i] = cmplx.Conj(a[i]) / complex(float64(n), 0)
+	}
+	return out
+}
@@ -0,0 +1,291 @@
+package ltspice
+
+import (
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dspTrace returns a trace of fn sampled uniformly at 2 MHz from 0 to 10 ms.
+func dspTrace(t *testing.T, fn func(float64) float64) *Trace[float64] {
+	sim := waveformSim(t, 10e-3, map[string]func(float64) float64{"V(x)": fn})
+	trace, err := GetTrace[float64](sim, "V(x)")
+	require.NoError(t, err)
+	return trace
+}
+
+// toneGain returns the amplitude of a filtered sine with the frequency f and unit amplitude, measured
+// by the RMS value of the second half of the trace, which spans an integer number of periods.
+func toneGain(t *testing.T, opts FilterOptions, f float64) float64 {
+	out, err := Filter(dspTrace(t, func(tm float64) float64 { return math.Sin(2 * math.Pi * f * tm) }), opts)
+	require.NoError(t, err)
+	y := out.GetSignal()
+	y = y[len(y)/2 : len(y)-1]
+	var p float64
+	for _, v := range y {
+		p += v * v
+	}
+	return math.Sqrt(2 * p / float64(len(y)))
+}
+
+func TestFilterIIR(t *testing.T) {
+	const fc = 10e3
+	lp := FilterOptions{Design: ButterworthFilter, Cutoff: fc}
+	assert.InDelta(t, 1, toneGain(t, lp, fc/10), 1e-3)
+	assert.InDelta(t, 1/math.Sqrt2, toneGain(t, lp, fc), 5e-3)
+	assert.Less(t, toneGain(t, lp, 10*fc), 2e-4)
+
+	hp := FilterOptions{Type: HighPass, Design: ButterworthFilter, Cutoff: fc, Order: 3}
+	assert.InDelta(t, 1, toneGain(t, hp, 10*fc), 1e-3)
+	assert.InDelta(t, 1/math.Sqrt2, toneGain(t, hp, fc), 5e-3)
+	assert.Less(t, toneGain(t, hp, fc/10), 2e-3)
+
+	// the pass band ripples between -1 dB and 0 dB
+	cheby := FilterOptions{Design: ChebyshevFilter, Cutoff: fc, Order: 5, Ripple: 1}
+	for _, f := range []float64{1e3, 3e3, 6e3, 8e3} {
+		g := toneGain(t, cheby, f)
+		assert.LessOrEqual(t, g, 1+5e-3, f)
+		assert.GreaterOrEqual(t, g, math.Pow(10, -1.0/20)-5e-3, f)
+	}
+	assert.InDelta(t, math.Pow(10, -1.0/20), toneGain(t, cheby, fc), 5e-3)
+	assert.Less(t, toneGain(t, cheby, 5*fc), toneGain(t, FilterOptions{Cutoff: fc, Order: 5}, 5*fc))
+}
+
+func TestFilterFIR(t *testing.T) {
+	const fc = 200e3
+	fir := FilterOptions{Design: FIRFilter, Cutoff: fc, Window: HannWindow}
+	assert.InDelta(t, 1, toneGain(t, fir, 20e3), 1e-3)
+	assert.InDelta(t, 0.5, toneGain(t, fir, fc), 3e-2)
+	assert.Less(t, toneGain(t, fir, 4*fc), 1e-2)
+
+	fir.Type = HighPass
+	assert.InDelta(t, 1, toneGain(t, fir, 4*fc), 1e-2)
+	assert.Less(t, toneGain(t, fir, 20e3), 1e-2)
+
+	ma := FilterOptions{Design: MovingAverageFilter, Cutoff: 10e3}
+	assert.InDelta(t, 1/math.Sqrt2, toneGain(t, ma, 10e3), 1e-2)
+	ma.Type = HighPass
+	assert.Less(t, toneGain(t, ma, 100), 1e-2)
+}
+
+func TestFilterSteadyState(t *testing.T) {
+	trace := dspTrace(t, func(float64) float64 { return 5 })
+	for _, design := range []FilterDesign{ButterworthFilter, ChebyshevFilter, FIRFilter, MovingAverageFilter} {
+		for _, typ := range []FilterType{LowPass, HighPass} {
+			out, err := Filter(trace, FilterOptions{Type: typ, Design: design, Cutoff: 50e3})
+			require.NoError(t, err)
+			want := 5.0
+			switch {
+			case typ == HighPass:
+				want = 0
+			case design == ChebyshevFilter:
+				// the gain of even order Chebyshev filters at DC is at the bottom of the ripple
+				want = 5 * math.Pow(10, -1.0/20)
+			}
+			for _, v := range out.GetSignal() {
+				require.InDelta(t, want, v, 1e-6, "%s %s", design, typ)
+			}
+		}
+	}
+}
+
+func TestFilterZeroPhase(t *testing.T) {
+	const fc = 10e3
+	in := dspTrace(t, func(tm float64) float64 { return math.Sin(2 * math.Pi * fc * tm) })
+	out, err := Filter(in, FilterOptions{Cutoff: fc, ZeroPhase: true})
+	require.NoError(t, err)
+	y, x := out.GetSignal(), in.GetSignal()
+	// the squared magnitude response halves the amplitude without shifting the phase
+	for i := range y {
+		require.InDelta(t, 0.5*x[i], y[i], 5e-3)
+	}
+}
+
+func TestDecimate(t *testing.T) {
+	in := dspTrace(t, func(tm float64) float64 { return math.Sin(2 * math.Pi * 1e3 * tm) })
+	out, err := Decimate(in, 10)
+	require.NoError(t, err)
+	require.Len(t, out.GetSignal(), 2001)
+	x := out.GetXAxis()
+	assert.InDelta(t, 5e-6, x[1]-x[0], 1e-15)
+	for i, v := range out.GetSignal() {
+		require.InDelta(t, math.Sin(2*math.Pi*1e3*x[i]), v, 1e-3)
+	}
+
+	// a tone above the new Nyquist frequency is removed
+	alias := dspTrace(t, func(tm float64) float64 { return math.Sin(2 * math.Pi * 150e3 * tm) })
+	out, err = Decimate(alias, 10)
+	require.NoError(t, err)
+	for _, v := range out.GetSignal()[100:1900] {
+		require.InDelta(t, 0, v, 1e-2)
+	}
+
+	_, err = Decimate(in, 0)
+	assert.Error(t, err)
+}
+
+func TestDetrendRemoveDC(t *testing.T) {
+	sine := func(tm float64) float64 { return math.Sin(2 * math.Pi * 10e3 * tm) }
+	in := dspTrace(t, func(tm float64) float64 { return 3 + 200*tm + sine(tm) })
+
+	out, err := Detrend(in)
+	require.NoError(t, err)
+	for i, v := range out.GetSignal() {
+		require.InDelta(t, sine(out.GetXAxis()[i]), v, 1e-2)
+	}
+
+	out, err = RemoveDC(dspTrace(t, func(tm float64) float64 { return 3 + sine(tm) }))
+	require.NoError(t, err)
+	for i, v := range out.GetSignal() {
+		require.InDelta(t, sine(out.GetXAxis()[i]), v, 1e-4)
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	am := func(tm float64) float64 { return 1 + 0.5*math.Cos(2*math.Pi*200*tm) }
+	in := dspTrace(t, func(tm float64) float64 { return am(tm) * math.Sin(2*math.Pi*50e3*tm) })
+	env, err := Envelope(in)
+	require.NoError(t, err)
+	y, x := env.GetSignal(), env.GetXAxis()
+	require.Len(t, y, len(in.Data))
+	for i := len(y) / 10; i < 9*len(y)/10; i++ {
+		require.InDelta(t, am(x[i]), y[i], 1e-2)
+	}
+
+	// the Hilbert transform of a cosine is a sine
+	a, err := Hilbert(dspTrace(t, func(tm float64) float64 { return math.Cos(2 * math.Pi * 1e3 * tm) }))
+	require.NoError(t, err)
+	x = a.GetXAxis()
+	for i := len(x) / 10; i < 9*len(x)/10; i++ {
+		require.InDelta(t, math.Cos(2*math.Pi*1e3*x[i]), real(a.Data[i]), 1e-9)
+		require.InDelta(t, math.Sin(2*math.Pi*1e3*x[i]), imag(a.Data[i]), 1e-2)
+	}
+}
+
+func TestDerivativeIntegral(t *testing.T) {
+	// a non-uniform x-axis, dense at the start like an LTSpice transient
+	var x, y []float64
+	for i := 0; i <= 2000; i++ {
+		v := 2 * math.Pi * math.Pow(float64(i)/2000, 2)
+		x = append(x, v)
+		y = append(y, math.Sin(v))
+	}
+	sim, err := newTableSim(TransientAnalysis, "nonuniform", []string{"time", "V(x)"}, [][]float64{x, y}, nil)
+	require.NoError(t, err)
+	trace, err := GetTrace[float64](sim, "V(x)")
+	require.NoError(t, err)
+
+	d, err := Derivative(trace)
+	require.NoError(t, err)
+	assert.Equal(t, "d(V(x))", d.Name)
+	assert.Equal(t, x, d.GetXAxis())
+	for i, v := range d.GetSignal()[1:1999] {
+		require.InDelta(t, math.Cos(x[i+1]), v, 1e-4)
+	}
+
+	idt, err := Integral(trace)
+	require.NoError(t, err)
+	assert.Equal(t, "idt(V(x))", idt.Name)
+	for i, v := range idt.GetSignal() {
+		require.InDelta(t, 1-math.Cos(x[i]), v, 1e-4)
+	}
+}
+
+func TestDSPStepped(t *testing.T) {
+	sim, err := Parse("testdata/simulations/stepped/rc/rc.raw")
+	require.NoError(t, err)
+	trace, err := GetTrace[float64](sim, "V(n002)")
+	require.NoError(t, err)
+	for step := 0; step < sim.GetSteps(); step++ {
+		assert.Equal(t, sim.GetXAxis(step), trace.GetXAxis(step))
+	}
+
+	// the capacitor current is C d(V(n002)), C is stepped from 100n to 600n
+	d, err := Derivative(trace)
+	require.NoError(t, err)
+	require.Equal(t, sim.GetSteps(), d.s.count)
+	current, err := GetTrace[float64](sim, "I(C1)")
+	require.NoError(t, err)
+	for step := 0; step < sim.GetSteps(); step++ {
+		dv, i := d.GetSignal(step), current.GetSignal(step)
+		require.Len(t, dv, len(i))
+		var di, dd float64
+		for j := range dv {
+			di += dv[j] * i[j]
+			dd += dv[j] * dv[j]
+		}
+		assert.InEpsilon(t, float64(step+1)*100e-9, di/dd, 5e-2)
+	}
+
+	// the integral of the capacitor current is the charge C V(n002)
+	q, err := Integral(current)
+	require.NoError(t, err)
+	for step := 0; step < sim.GetSteps(); step++ {
+		v, got := trace.GetSignal(step), q.GetSignal(step)
+		c := float64(step+1) * 100e-9
+		assert.InDelta(t, c*(v[len(v)-1]-v[0]), got[len(got)-1], 5e-2*c)
+	}
+
+	// the non-uniform steps are resampled with the same number of points
+	filtered, err := Filter(trace, FilterOptions{Cutoff: 1e3})
+	require.NoError(t, err)
+	for step := 0; step < sim.GetSteps(); step++ {
+		x := filtered.GetXAxis(step)
+		require.Len(t, x, len(sim.GetXAxis(step)))
+		assert.True(t, isUniform(x))
+	}
+
+	uniform, err := Uniform(trace, ResampleOptions{Step: 1e-5})
+	require.NoError(t, err)
+	x := uniform.GetXAxis(2)
+	assert.InDelta(t, 1e-5, x[1]-x[0], 1e-12)
+
+	last := sim.GetXAxis(2)[len(sim.GetXAxis(2))-1]
+	uniform, err = Uniform(trace, ResampleOptions{Start: last / 2, Points: 100})
+	require.NoError(t, err)
+	x = uniform.GetXAxis(2)
+	assert.Equal(t, last/2, x[0])
+	assert.InDelta(t, last, x[99], 1e-15)
+}
+
+func TestTraceXAxis(t *testing.T) {
+	sim, err := Parse("testdata/simulations/ac/low-pass/low-pass-filter.raw")
+	require.NoError(t, err)
+	trace, err := GetTrace[complex128](sim, "V(n002)")
+	require.NoError(t, err)
+	x := trace.GetXAxis()
+	require.Len(t, x, len(trace.Data))
+	assert.Equal(t, sim.GetXAxis(), x)
+
+	// the trace is safe for concurrent use
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, x, trace.GetXAxis())
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDSPErrors(t *testing.T) {
+	trace := dspTrace(t, math.Sin)
+	_, err := Filter(trace, FilterOptions{Cutoff: 2e6})
+	assert.Error(t, err)
+	_, err = Filter(trace, FilterOptions{})
+	assert.Error(t, err)
+	_, err = Filter(trace, FilterOptions{Type: HighPass, Design: FIRFilter, Cutoff: 1e3, Order: 11})
+	assert.Error(t, err)
+	_, err = Filter(trace, FilterOptions{Cutoff: 1e3, Order: -1})
+	assert.Error(t, err)
+
+	bare := &Trace[float64]{Name: "V(bare)", Data: []float64{1, 2, 3}}
+	_, err = Filter(bare, FilterOptions{Cutoff: 1})
+	assert.Error(t, err)
+	_, err = Derivative(bare)
+	assert.Error(t, err)
+}
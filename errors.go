@@ -12,4 +12,10 @@ var (
 	ErrParseStepInfo            = errors.New("parse error: failed to parse step info for stepped simulation")
 	ErrTraceDoesNotExist        = errors.New("trace not found")
 	ErrInvaleTraceTypeAssertion = errors.New("type assertion failed")
+	ErrInvalidTimescale         = errors.New("invalid timescale")
+	ErrInvalidTouchstone        = errors.New("invalid touchstone file")
+	ErrSingularMatrix           = errors.New("singular matrix")
+	ErrSimulationFailed         = errors.New("simulation failed")
+	ErrConvergence              = errors.New("convergence failure")
+	ErrNoOperatingPoint         = errors.New("no operating point")
 )
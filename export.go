@@ -0,0 +1,231 @@
+package ltspice
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteCSV writes the simulation as comma separated values.
+//
+// The first column holds the step index followed by the x-axis and one column per trace.
+// Complex traces are split into two columns suffixed with "_re" and "_im".
+// Use SelectTraces and SelectSteps to export a subset of the simulation.
+func WriteCSV(w io.Writer, sim *SimData) error {
+	cw := csv.NewWriter(w)
+	isComplex := sim.Meta.Flags.hasFlag(Complex)
+
+	header := []string{"step", sim.xAxisLabel}
+	for _, v := range sim.Meta.Variables[1:] {
+		if isComplex {
+			header = append(header, v.Name+"_re", v.Name+"_im")
+		} else {
+			header = append(header, v.Name)
+		}
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	xAxis := sim.flatXAxis()
+	record := make([]string, len(header))
+	for step := 0; step < sim.steps.count; step++ {
+		start, end := sim.stepBounds(step)
+		for i := start; i < end; i++ {
+			record[0] = strconv.Itoa(step)
+			record[1] = formatFloat(xAxis[i])
+			col := 2
+			for _, v := range sim.Meta.Variables[1:] {
+				if isComplex {
+					c := sim.complexData[v.Name][i]
+					record[col], record[col+1] = formatFloat(real(c)), formatFloat(imag(c))
+					col += 2
+				} else {
+					record[col] = formatFloat(sim.data[v.Name][i])
+					col++
+				}
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type jsonSimulation struct {
+	Title     string         `json:"title"`
+	Date      time.Time      `json:"date"`
+	PlotName  string         `json:"plotname"`
+	Flags     string         `json:"flags"`
+	Command   string         `json:"command"`
+	Points    int            `json:"points"`
+	Offset    float64        `json:"offset"`
+	Variables []jsonVariable `json:"variables"`
+	Steps     []jsonStep     `json:"steps"`
+}
+
+type jsonVariable struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type jsonStep struct {
+	Step   int            `json:"step"`
+	XAxis  []float64      `json:"x"`
+	Traces map[string]any `json:"traces"`
+}
+
+// WriteJSON writes the simulation as a JSON document holding the metadata, the list of
+// variables and the data of every step. Complex values are written as [re, im] pairs.
+// Use SelectTraces and SelectSteps to export a subset of the simulation.
+func WriteJSON(w io.Writer, sim *SimData) error {
+	meta := sim.Meta
+	doc := jsonSimulation{
+		Title:    meta.Title,
+		Date:     meta.Date,
+		PlotName: meta.SimType.String(),
+		Flags:    meta.Flags.String(),
+		Command:  meta.Command,
+		Points:   meta.NoPoints,
+		Offset:   meta.Offset,
+	}
+	for _, v := range meta.Variables {
+		doc.Variables = append(doc.Variables, jsonVariable{Name: v.Name, Type: v.Typ})
+	}
+
+	xAxis := sim.flatXAxis()
+	for step := 0; step < sim.steps.count; step++ {
+		start, end := sim.stepBounds(step)
+		s := jsonStep{Step: step, XAxis: xAxis[start:end], Traces: make(map[string]any, len(meta.Variables)-1)}
+		for _, v := range meta.Variables[1:] {
+			if meta.Flags.hasFlag(Complex) {
+				values := sim.complexData[v.Name][start:end]
+				pairs := make([][2]float64, len(values))
+				for i, c := range values {
+					pairs[i] = [2]float64{real(c), imag(c)}
+				}
+				s.Traces[v.Name] = pairs
+			} else {
+				s.Traces[v.Name] = sim.data[v.Name][start:end]
+			}
+		}
+		doc.Steps = append(doc.Steps, s)
+	}
+
+	enc := json.NewEncoder(w)
+	return enc.Encode(doc)
+}
+
+// ReadJSON reads a simulation written by WriteJSON.
+func ReadJSON(r io.Reader) (*SimData, error) {
+	var doc struct {
+		jsonSimulation
+		Steps []struct {
+			Step   int                        `json:"step"`
+			XAxis  []float64                  `json:"x"`
+			Traces map[string]json.RawMessage `json:"traces"`
+		} `json:"steps"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrParsingError, err)
+	}
+	if len(doc.Variables) == 0 || len(doc.Steps) == 0 {
+		return nil, fmt.Errorf("%w: simulation has no variables or steps", ErrParsingError)
+	}
+
+	meta := &MetaData{
+		Title:       doc.Title,
+		Date:        doc.Date,
+		Command:     doc.Command,
+		Offset:      doc.Offset,
+		NoVariables: len(doc.Variables),
+	}
+	simType, ok := simTypeFromName(doc.PlotName)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSimulationType, doc.PlotName)
+	}
+	meta.SimType = simType
+	if doc.Flags != "" {
+		meta.Flags = parseFlags(strings.Split(doc.Flags, "|")...)
+	}
+	if !meta.Flags.hasFlag(Complex) {
+		meta.Flags.setFlag(None)
+	}
+	for i, v := range doc.Variables {
+		meta.Variables = append(meta.Variables, Variable{order: i, Name: v.Name, Typ: v.Type})
+	}
+
+	sim := &SimData{Meta: meta, xAxisLabel: doc.Variables[0].Name, steps: &steps{}}
+	isComplex := meta.Flags.hasFlag(Complex)
+	if isComplex {
+		sim.complexData = make(map[string][]complex128, len(doc.Variables))
+	} else {
+		sim.data = make(map[string][]float64, len(doc.Variables))
+	}
+	for _, step := range doc.Steps {
+		sim.steps.offsets = append(sim.steps.offsets, meta.NoPoints)
+		sim.steps.count++
+		n := len(step.XAxis)
+		for i, v := range doc.Variables {
+			if i == 0 {
+				if isComplex {
+					for _, x := range step.XAxis {
+						sim.complexData[v.Name] = append(sim.complexData[v.Name], complex(x, 0))
+					}
+				} else {
+					sim.data[v.Name] = append(sim.data[v.Name], step.XAxis...)
+				}
+				continue
+			}
+			raw, ok := step.Traces[v.Name]
+			if !ok {
+				return nil, fmt.Errorf("%w: step %d has no data for %s", ErrTraceDoesNotExist, step.Step, v.Name)
+			}
+			var err error
+			if isComplex {
+				var pairs [][2]float64
+				err = json.Unmarshal(raw, &pairs)
+				for _, p := range pairs {
+					sim.complexData[v.Name] = append(sim.complexData[v.Name], complex(p[0], p[1]))
+				}
+				if err == nil && len(pairs) != n {
+					err = fmt.Errorf("expected %d points, got %d", n, len(pairs))
+				}
+			} else {
+				var values []float64
+				err = json.Unmarshal(raw, &values)
+				sim.data[v.Name] = append(sim.data[v.Name], values...)
+				if err == nil && len(values) != n {
+					err = fmt.Errorf("expected %d points, got %d", n, len(values))
+				}
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%w: trace %s in step %d: %v", ErrParsingError, v.Name, step.Step, err)
+			}
+		}
+		meta.NoPoints += n
+	}
+	sim.stepPoints = meta.NoPoints
+	return sim, nil
+}
+
+// simTypeFromName maps the plot name written by SimType.String back to the simulation type.
+func simTypeFromName(name string) (SimType, bool) {
+	for t := OperatingPoint; t <= TransferFunction; t++ {
+		if t.String() == name {
+			return t, true
+		}
+	}
+	t, err := simTypeFromString(name)
+	return t, err == nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
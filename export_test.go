@@ -0,0 +1,114 @@
+package ltspice
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCSV(t *testing.T) {
+	sim, err := Parse("testdata/simulations/trans/stepped2/TRAN-STEP.raw")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, sim))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"step", "time", "V(in)", "V(out)", "I(C1)", "I(R1)", "I(Vin)"}, records[0])
+	require.Len(t, records, sim.Meta.NoPoints+1)
+
+	last := records[len(records)-1]
+	assert.Equal(t, strconv.Itoa(sim.GetSteps()-1), last[0])
+	vout, err := strconv.ParseFloat(last[3], 64)
+	require.NoError(t, err)
+	assert.Equal(t, sim.data["V(out)"][sim.Meta.NoPoints-1], vout)
+}
+
+func TestWriteCSVComplex(t *testing.T) {
+	sim, err := Parse("testdata/simulations/ac/low-pass/low-pass-filter.raw")
+	require.NoError(t, err)
+	sim, err = sim.SelectTraces("V(n002)")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, sim))
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"step", "frequency", "V(n002)_re", "V(n002)_im"}, records[0])
+	im, err := strconv.ParseFloat(records[1][3], 64)
+	require.NoError(t, err)
+	assert.Equal(t, imag(sim.complexData["V(n002)"][0]), im)
+}
+
+func TestWriteJSON(t *testing.T) {
+	sim, err := Parse("testdata/simulations/ac/low-pass/low-pass-filter.raw")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, sim))
+
+	var doc struct {
+		PlotName  string `json:"plotname"`
+		Variables []struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+		} `json:"variables"`
+		Steps []struct {
+			Step   int                     `json:"step"`
+			X      []float64               `json:"x"`
+			Traces map[string][][2]float64 `json:"traces"`
+		} `json:"steps"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, "AC Analysis", doc.PlotName)
+	assert.Len(t, doc.Variables, sim.Meta.NoVariables)
+	require.Len(t, doc.Steps, 1)
+	assert.Equal(t, sim.GetXAxis(), doc.Steps[0].X)
+	c := sim.complexData["I(C1)"][1]
+	assert.Equal(t, [2]float64{real(c), imag(c)}, doc.Steps[0].Traces["I(C1)"][1])
+}
+
+func TestReadJSON(t *testing.T) {
+	for _, file := range []string{
+		"testdata/simulations/trans/stepped2/TRAN-STEP.raw",
+		"testdata/simulations/ac/low-pass/low-pass-filter.raw",
+		"testdata/simulations/op/iter/iter.raw",
+	} {
+		t.Run(file, func(t *testing.T) {
+			sim, err := Parse(file)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			require.NoError(t, WriteJSON(&buf, sim))
+			got, err := ReadJSON(&buf)
+			require.NoError(t, err)
+
+			assert.Equal(t, sim.GetType(), got.GetType())
+			assert.Equal(t, sim.Meta.Flags, got.Meta.Flags)
+			assert.Equal(t, sim.Meta.NoPoints, got.Meta.NoPoints)
+			require.Len(t, got.Meta.Variables, len(sim.Meta.Variables))
+			for i, v := range sim.Meta.Variables {
+				assert.Equal(t, v.Name, got.Meta.Variables[i].Name)
+				assert.Equal(t, v.Typ, got.Meta.Variables[i].Typ)
+			}
+			assert.Equal(t, sim.GetSteps(), got.GetSteps())
+			for step := 0; step < sim.GetSteps(); step++ {
+				assert.Equal(t, sim.GetXAxis(step), got.GetXAxis(step))
+			}
+			report, err := Compare(sim, got, CompareOptions{})
+			require.NoError(t, err)
+			assert.True(t, report.Passed())
+		})
+	}
+
+	_, err := ReadJSON(bytes.NewBufferString(`{"plotname": "Bogus", "variables": [{"name": "time"}], "steps": [{"x": [0]}]}`))
+	assert.ErrorIs(t, err, ErrInvalidSimulationType)
+	_, err = ReadJSON(bytes.NewBufferString(`{`))
+	assert.ErrorIs(t, err, ErrParsingError)
+}
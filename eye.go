@@ -0,0 +1,328 @@
+package ltspice
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+)
+
+// EyeOptions configures Eye.
+type EyeOptions struct {
+	// UnitInterval is the duration of a bit (or symbol) in seconds.
+	UnitInterval float64
+	// Offset is the time of a nominal crossing of the data. It is ignored if RecoverClock is set.
+	Offset float64
+	// RecoverClock estimates the phase and the unit interval of the data from its crossings of the threshold,
+	// like an ideal clock data recovery with a constant frequency.
+	RecoverClock bool
+	// Threshold is the decision threshold. Defaults to the middle between the minimum and maximum of the window.
+	Threshold float64
+	// Start and Stop limit the eye to a window of the time axis, e.g. to skip the start-up of the link.
+	// Zero values select the first and last point of each step.
+	Start, Stop float64
+	// TimeBins and ValueBins are the resolution of the density histogram, defaults to 100 bins per unit
+	// interval and 100 value bins.
+	TimeBins, ValueBins int
+}
+
+// Eye is the eye diagram of a data signal folded by its unit interval.
+type Eye struct {
+	UnitInterval float64
+	// Offset is the time of the mean crossing of the data.
+	Offset    float64
+	Threshold float64
+	// Histogram holds the number of samples per value bin (rows, from Max down to Min) and time bin (columns).
+	// The histogram spans two unit intervals with the eye centered and a crossing at a quarter and three
+	// quarters of its width.
+	Histogram [][]int
+	// Min and Max are the values of the bottom and top edge of the histogram.
+	Min, Max float64
+	// Height is the smallest vertical opening at the center of the eye, Width the horizontal opening at the
+	// threshold, both are zero for a closed eye.
+	Height, Width float64
+	// JitterRMS and JitterPP are the RMS and peak-to-peak deviation of the crossings from the recovered clock.
+	JitterRMS, JitterPP float64
+	// CrossingPercentage is the mean level of the crossings relative to the zero and one level, 50% for
+	// a symmetric eye.
+	CrossingPercentage float64
+}
+
+// Eye folds a trace of a transient analysis by the unit interval and computes the eye metrics. For stepped
+// simulations a step index selects the step, the first step is used if none is given.
+//
+// Example usage:
+//
+//	eye, err := simData.Eye("V(rx)", ltspice.EyeOptions{UnitInterval: 100e-12, RecoverClock: true, Start: 10e-9})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("eye height %g V, width %g s\n", eye.Height, eye.Width)
+//	err = ltspice.WriteEyePNG(file, eye)
+func (sim *SimData) Eye(name string, opts EyeOptions, step ...int) (*Eye, error) {
+	if opts.UnitInterval <= 0 {
+		return nil, errors.New("eye: unit interval must be positive")
+	}
+	if opts.TimeBins < 0 || opts.ValueBins < 0 {
+		return nil, errors.New("eye: bins must not be negative")
+	}
+	if opts.TimeBins == 0 {
+		opts.TimeBins = 100
+	}
+	if opts.ValueBins == 0 {
+		opts.ValueBins = 100
+	}
+	x, y, err := sim.waveform(name, WaveformOptions{Start: opts.Start, Stop: opts.Stop}, step...)
+	if err != nil {
+		return nil, err
+	}
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range y {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if hi <= lo {
+		return nil, fmt.Errorf("eye: %s is constant", name)
+	}
+	eye := &Eye{UnitInterval: opts.UnitInterval, Offset: opts.Offset, Threshold: opts.Threshold}
+	if eye.Threshold == 0 {
+		eye.Threshold = (lo + hi) / 2
+	}
+	var times []float64
+	for _, c := range levelCrossings(x, y, eye.Threshold) {
+		times = append(times, c.x)
+	}
+	if len(times) == 0 {
+		return nil, fmt.Errorf("eye: %s does not cross the threshold %g", name, eye.Threshold)
+	}
+	if opts.RecoverClock {
+		eye.UnitInterval, eye.Offset = recoverClock(times, eye.UnitInterval)
+	}
+	ui := eye.UnitInterval
+
+	// deviation of the crossings from the clock
+	var mean float64
+	dev := make([]float64, len(times))
+	for i, t := range times {
+		dev[i] = t - eye.Offset - ui*math.Round((t-eye.Offset)/ui)
+		mean += dev[i]
+	}
+	mean /= float64(len(dev))
+	eye.Offset += mean
+	minDev, maxDev := math.Inf(1), math.Inf(-1)
+	for i := range dev {
+		dev[i] -= mean
+		eye.JitterRMS += dev[i] * dev[i]
+		minDev, maxDev = math.Min(minDev, dev[i]), math.Max(maxDev, dev[i])
+	}
+	eye.JitterRMS = math.Sqrt(eye.JitterRMS / float64(len(dev)))
+	eye.JitterPP = maxDev - minDev
+	eye.Width = math.Max(0, ui-eye.JitterPP)
+
+	// decide the bits at the center of each unit interval
+	first := math.Ceil((x[0] - eye.Offset - ui/2) / ui)
+	var centers []float64
+	for n := first; eye.Offset+ui/2+n*ui <= x[len(x)-1]; n++ {
+		centers = append(centers, interpLinear(x, y, eye.Offset+ui/2+n*ui))
+	}
+	ones, zeros := math.Inf(1), math.Inf(-1)
+	var oneLevel, zeroLevel, crossLevel float64
+	var nOnes, nZeros, nCross int
+	for i, v := range centers {
+		if v > eye.Threshold {
+			ones = math.Min(ones, v)
+			oneLevel += v
+			nOnes++
+		} else {
+			zeros = math.Max(zeros, v)
+			zeroLevel += v
+			nZeros++
+		}
+		if i > 0 && (v > eye.Threshold) != (centers[i-1] > eye.Threshold) {
+			crossLevel += interpLinear(x, y, eye.Offset+(first+float64(i))*ui)
+			nCross++
+		}
+	}
+	if nOnes > 0 && nZeros > 0 {
+		eye.Height = math.Max(0, ones-zeros)
+		oneLevel, zeroLevel = oneLevel/float64(nOnes), zeroLevel/float64(nZeros)
+		if nCross > 0 {
+			eye.CrossingPercentage = 100 * (crossLevel/float64(nCross) - zeroLevel) / (oneLevel - zeroLevel)
+		}
+	}
+
+	// density histogram of the uniformly resampled trace over two unit intervals
+	margin := 0.05 * (hi - lo)
+	eye.Min, eye.Max = lo-margin, hi+margin
+	eye.Histogram = make([][]int, opts.ValueBins)
+	for i := range eye.Histogram {
+		eye.Histogram[i] = make([]int, 2*opts.TimeBins)
+	}
+	dt := ui / float64(opts.TimeBins)
+	start := eye.Offset + ui/2 - ui
+	for i, n := 0, int((x[len(x)-1]-x[0])/dt); i <= n; i++ {
+		t := x[0] + float64(i)*dt
+		phase := math.Mod(t-start, ui)
+		if phase < 0 {
+			phase += ui
+		}
+		col := min(int(phase/dt), opts.TimeBins-1)
+		row := opts.ValueBins - 1 - int((interpLinear(x, y, t)-eye.Min)/(eye.Max-eye.Min)*float64(opts.ValueBins))
+		row = max(0, min(row, opts.ValueBins-1))
+		eye.Histogram[row][col]++
+		eye.Histogram[row][col+opts.TimeBins]++
+	}
+	return eye, nil
+}
+
+// recoverClock fits a clock with a constant unit interval, starting from the nominal ui, to the crossing times.
+// It returns the unit interval and the time of a crossing.
+func recoverClock(times []float64, ui float64) (float64, float64) {
+	// phase from the circular mean of the crossings
+	var s, c float64
+	for _, t := range times {
+		phi := 2 * math.Pi * math.Mod(t, ui) / ui
+		s += math.Sin(phi)
+		c += math.Cos(phi)
+	}
+	offset := ui * math.Atan2(s, c) / (2 * math.Pi)
+	if len(times) < 2 {
+		return ui, offset
+	}
+	// least squares fit of t = offset + n*ui with the bit index n of each crossing
+	for iter := 0; iter < 3; iter++ {
+		var sn, st, snn, snt float64
+		for _, t := range times {
+			n := math.Round((t - offset) / ui)
+			sn += n
+			st += t
+			snn += n * n
+			snt += n * t
+		}
+		m := float64(len(times))
+		det := m*snn - sn*sn
+		if det == 0 {
+			break
+		}
+		ui = (m*snt - sn*st) / det
+		offset = (st - ui*sn) / m
+	}
+	return ui, offset
+}
+
+// TimeAxis returns the times of the centers of the time bins of the histogram relative to the eye center.
+func (e *Eye) TimeAxis() []float64 {
+	cols := len(e.Histogram[0])
+	axis := make([]float64, cols)
+	for i := range axis {
+		axis[i] = (float64(i)+0.5)*2*e.UnitInterval/float64(cols) - e.UnitInterval
+	}
+	return axis
+}
+
+// ValueAxis returns the values of the centers of the value bins of the histogram, from top to bottom.
+func (e *Eye) ValueAxis() []float64 {
+	rows := len(e.Histogram)
+	axis := make([]float64, rows)
+	for i := range axis {
+		axis[i] = e.Max - (float64(i)+0.5)*(e.Max-e.Min)/float64(rows)
+	}
+	return axis
+}
+
+// Image renders the density histogram with one pixel per bin. The density is mapped logarithmically
+// from dark blue to yellow, empty bins are black.
+func (e *Eye) Image() image.Image {
+	rows, cols := len(e.Histogram), len(e.Histogram[0])
+	img := image.NewRGBA(image.Rect(0, 0, cols, rows))
+	peak := 0
+	for _, row := range e.Histogram {
+		for _, n := range row {
+			peak = max(peak, n)
+		}
+	}
+	for r, row := range e.Histogram {
+		for c, n := range row {
+			img.Set(c, r, eyeColor(n, peak))
+		}
+	}
+	return img
+}
+
+func eyeColor(n, peak int) color.RGBA {
+	if n == 0 || peak == 0 {
+		return color.RGBA{A: 255}
+	}
+	v := math.Log1p(float64(n)) / math.Log1p(float64(peak))
+	return color.RGBA{R: uint8(255 * v), G: uint8(255 * v * v), B: uint8(255 * (1 - v) * 0.8), A: 255}
+}
+
+// WriteEyePNG writes the density histogram of the eye as a PNG image, see Eye.Image.
+func WriteEyePNG(w io.Writer, eye *Eye) error {
+	return png.Encode(w, eye.Image())
+}
+
+// WriteEyeSVG writes the density histogram of the eye as an SVG image with one rectangle per non-empty bin,
+// the threshold and the eye center as dashed lines.
+func WriteEyeSVG(w io.Writer, eye *Eye) error {
+	const scale = 4
+	rows, cols := len(eye.Histogram), len(eye.Histogram[0])
+	peak := 0
+	for _, row := range eye.Histogram {
+		for _, n := range row {
+			peak = max(peak, n)
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	width, height := cols*scale, rows*scale
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="black"/>`+"\n", width, height)
+	for r, row := range eye.Histogram {
+		for c, n := range row {
+			if n == 0 {
+				continue
+			}
+			col := eyeColor(n, peak)
+			fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="#%02x%02x%02x"/>`+"\n", c*scale, r*scale, scale, scale, col.R, col.G, col.B)
+		}
+	}
+	ty := (eye.Max - eye.Threshold) / (eye.Max - eye.Min) * float64(height)
+	fmt.Fprintf(bw, `<line x1="0" y1="%s" x2="%d" y2="%s" stroke="white" stroke-dasharray="4"/>`+"\n", formatFloat(ty), width, formatFloat(ty))
+	fmt.Fprintf(bw, `<line x1="%d" y1="0" x2="%d" y2="%d" stroke="white" stroke-dasharray="4"/>`+"\n", width/2, width/2, height)
+	fmt.Fprintf(bw, `<title>UI %s s, height %s, width %s s</title>`+"\n", formatFloat(eye.UnitInterval), formatFloat(eye.Height), formatFloat(eye.Width))
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+// WriteEyeCSV writes the density histogram of the eye as a 2D array. The first row holds the times of the
+// time bins relative to the eye center, the first column the values of the value bins.
+func WriteEyeCSV(w io.Writer, eye *Eye) error {
+	cw := csv.NewWriter(w)
+	header := []string{"value"}
+	for _, t := range eye.TimeAxis() {
+		header = append(header, formatFloat(t))
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	values := eye.ValueAxis()
+	record := make([]string, len(header))
+	for r, row := range eye.Histogram {
+		record[0] = formatFloat(values[r])
+		for c, n := range row {
+			record[c+1] = strconv.Itoa(n)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,199 @@
+package ltspice
+
+import (
+	"bytes"
+	"encoding/csv"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	eyeUI     = 1e-6
+	eyeOffset = 0.3 * eyeUI
+	eyeEdge   = 0.2 * eyeUI
+)
+
+// prbs7 returns the 127 bits of the PRBS7 sequence x^7 + x^6 + 1.
+func prbs7() []bool {
+	bits := make([]bool, 127)
+	state := uint8(0x7f)
+	for i := range bits {
+		b := (state>>6 ^ state>>5) & 1
+		state = state<<1&0x7f | b
+		bits[i] = b == 1
+	}
+	return bits
+}
+
+// eyeJitter is the deviation of the edge k from the ideal clock.
+func eyeJitter(k int) float64 {
+	return 0.02 * eyeUI * math.Sin(2*math.Pi*float64(k)/7.3)
+}
+
+// nrzSim returns a transient analysis of a PRBS7 NRZ signal between 0 and 1 V with raised cosine edges and
+// sinusoidal jitter.
+func nrzSim(t *testing.T) *SimData {
+	bits := prbs7()
+	level := func(k int) float64 {
+		if k < 0 || !bits[k%len(bits)] {
+			return 0
+		}
+		return 1
+	}
+	return waveformSim(t, float64(len(bits))*eyeUI, map[string]func(float64) float64{
+		"V(rx)": func(tm float64) float64 {
+			k := int(math.Round((tm - eyeOffset) / eyeUI))
+			edge := eyeOffset + float64(k)*eyeUI + eyeJitter(k)
+			switch {
+			case tm < edge-eyeEdge/2:
+				return level(k - 1)
+			case tm > edge+eyeEdge/2:
+				return level(k)
+			}
+			s := (1 - math.Cos(math.Pi*(tm-edge+eyeEdge/2)/eyeEdge)) / 2
+			return level(k-1) + s*(level(k)-level(k-1))
+		},
+	})
+}
+
+// nrzJitter returns the RMS and peak-to-peak jitter of the edges of nrzSim with a transition.
+func nrzJitter() (float64, float64) {
+	bits := prbs7()
+	var dev []float64
+	var mean float64
+	for k := 1; k < len(bits); k++ {
+		if bits[k] != bits[k-1] {
+			dev = append(dev, eyeJitter(k))
+			mean += eyeJitter(k)
+		}
+	}
+	mean /= float64(len(dev))
+	var rms float64
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, d := range dev {
+		rms += (d - mean) * (d - mean)
+		lo, hi = math.Min(lo, d), math.Max(hi, d)
+	}
+	return math.Sqrt(rms / float64(len(dev))), hi - lo
+}
+
+func TestEye(t *testing.T) {
+	sim := nrzSim(t)
+	rms, pp := nrzJitter()
+
+	eye, err := sim.Eye("V(rx)", EyeOptions{UnitInterval: eyeUI, Offset: eyeOffset})
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, eye.Threshold)
+	assert.InDelta(t, 1, eye.Height, 1e-9)
+	assert.InDelta(t, rms, eye.JitterRMS, 5e-4*eyeUI)
+	assert.InDelta(t, pp, eye.JitterPP, 1e-3*eyeUI)
+	assert.InDelta(t, eyeUI-pp, eye.Width, 1e-3*eyeUI)
+	assert.InDelta(t, 50, eye.CrossingPercentage, 2)
+
+	require.Len(t, eye.Histogram, 100)
+	require.Len(t, eye.Histogram[0], 200)
+	var total int
+	for _, row := range eye.Histogram {
+		for _, n := range row {
+			total += n
+		}
+	}
+	assert.Equal(t, 2*(127*100+1), total)
+
+	// the eye is open at its center and closed at the crossings
+	var crossings int
+	for r, v := range eye.ValueAxis() {
+		if math.Abs(v-0.5) < 0.1 {
+			assert.Zero(t, eye.Histogram[r][100], v)
+			for c := 45; c < 55; c++ {
+				crossings += eye.Histogram[r][c] + eye.Histogram[r][c+100]
+			}
+		}
+	}
+	assert.NotZero(t, crossings)
+	times := eye.TimeAxis()
+	assert.InDelta(t, 0, times[100], eyeUI/100)
+	assert.InDelta(t, -eyeUI/2, times[50], eyeUI/100)
+}
+
+func TestEyeRecoverClock(t *testing.T) {
+	sim := nrzSim(t)
+	rms, pp := nrzJitter()
+
+	eye, err := sim.Eye("V(rx)", EyeOptions{UnitInterval: 1.002 * eyeUI, RecoverClock: true, TimeBins: 64, ValueBins: 32})
+	require.NoError(t, err)
+	assert.InEpsilon(t, eyeUI, eye.UnitInterval, 1e-4)
+	offset := math.Mod(eye.Offset, eyeUI)
+	if offset < 0 {
+		offset += eyeUI
+	}
+	// the clock is fitted to the jittered crossings, so it deviates slightly from the ideal clock
+	assert.InDelta(t, eyeOffset, offset, 1e-2*eyeUI)
+	assert.InDelta(t, rms, eye.JitterRMS, 2e-3*eyeUI)
+	assert.InDelta(t, pp, eye.JitterPP, 1e-2*eyeUI)
+	assert.InDelta(t, 1, eye.Height, 1e-9)
+	assert.Len(t, eye.Histogram, 32)
+	assert.Len(t, eye.Histogram[0], 128)
+}
+
+func TestEyeClosed(t *testing.T) {
+	sim := nrzSim(t)
+	// folding by the wrong unit interval smears the crossings across the eye
+	eye, err := sim.Eye("V(rx)", EyeOptions{UnitInterval: 1.37 * eyeUI, Offset: eyeOffset})
+	require.NoError(t, err)
+	assert.Less(t, eye.Width, 0.1*eye.UnitInterval)
+	assert.Less(t, eye.Height, 0.9)
+}
+
+func TestEyeErrors(t *testing.T) {
+	sim := nrzSim(t)
+	_, err := sim.Eye("V(rx)", EyeOptions{})
+	assert.Error(t, err)
+	_, err = sim.Eye("V(rx)", EyeOptions{UnitInterval: eyeUI, TimeBins: -1})
+	assert.Error(t, err)
+	_, err = sim.Eye("V(missing)", EyeOptions{UnitInterval: eyeUI})
+	assert.ErrorIs(t, err, ErrTraceDoesNotExist)
+	_, err = sim.Eye("V(rx)", EyeOptions{UnitInterval: eyeUI, Threshold: 2})
+	assert.Error(t, err)
+
+	ac, err := Parse("testdata/simulations/ac/low-pass/low-pass-filter.raw")
+	require.NoError(t, err)
+	_, err = ac.Eye("V(n002)", EyeOptions{UnitInterval: eyeUI})
+	assert.ErrorIs(t, err, ErrInvalidSimulationType)
+}
+
+func TestWriteEye(t *testing.T) {
+	sim := nrzSim(t)
+	eye, err := sim.Eye("V(rx)", EyeOptions{UnitInterval: eyeUI, Offset: eyeOffset, TimeBins: 20, ValueBins: 10})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteEyePNG(&buf, eye))
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 40, img.Bounds().Dx())
+	assert.Equal(t, 10, img.Bounds().Dy())
+
+	buf.Reset()
+	require.NoError(t, WriteEyeSVG(&buf, eye))
+	svg := buf.String()
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="160" height="40"`))
+	assert.True(t, strings.HasSuffix(svg, "</svg>\n"))
+
+	buf.Reset()
+	require.NoError(t, WriteEyeCSV(&buf, eye))
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 11)
+	assert.Equal(t, "value", records[0][0])
+	for _, r := range records {
+		assert.Len(t, r, 41)
+	}
+	assert.Equal(t, formatFloat(eye.ValueAxis()[0]), records[1][0])
+	assert.Equal(t, formatFloat(eye.TimeAxis()[0]), records[0][1])
+}
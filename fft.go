@@ -0,0 +1,244 @@
+package ltspice
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// Window is a window function applied to the samples of an FFT.
+type Window int
+
+const (
+	RectangularWindow Window = iota
+	HannWindow
+	// BlackmanHarrisWindow is the 4-term Blackman-Harris window with side lobes below -92 dB.
+	BlackmanHarrisWindow
+	// FlatTopWindow has a flat main lobe, so the amplitude of tones between bins is accurate.
+	FlatTopWindow
+	// KaiserWindow is the Kaiser-Bessel window, its shape is set by FFTOptions.Beta.
+	KaiserWindow
+)
+
+func (w Window) String() string {
+	return [...]string{"rectangular", "hann", "blackman-harris", "flat-top", "kaiser"}[w]
+}
+
+// defaultKaiserBeta gives side lobes around -90 dB.
+const defaultKaiserBeta = 12
+
+// FFTOptions configures FFT.
+type FFTOptions struct {
+	Window Window
+	// Beta is the shape parameter of the Kaiser window, defaults to 12.
+	Beta float64
+	// Start and Stop limit the FFT to a window of the time axis. Zero values select the first and last
+	// point of each step. For an accurate spectrum the window should span an integer number of periods.
+	Start, Stop float64
+	// Points is the number of uniform samples, rounded up to a power of two. Defaults to the number of points
+	// of the step rounded up to a power of two.
+	Points int
+	// Method is the interpolation used to resample the trace uniformly.
+	Method Interpolation
+}
+
+// FFT computes the single-sided spectrum of the given traces, or of all traces if none are given, of a
+// transient analysis. Each step of a stepped simulation is transformed on its own.
+//
+// The traces are resampled uniformly, multiplied with the window and transformed. The result is an AC
+// analysis with a frequency x-axis from DC to the Nyquist frequency, so the complex accessors and exporters
+// can be used on it. The spectrum is scaled to the amplitude of the input: a sine with amplitude A shows
+// up as a bin with magnitude A, the DC bin holds the mean value.
+//
+// Example usage:
+//
+//	spectrum, err := simData.FFT(ltspice.FFTOptions{Window: ltspice.BlackmanHarrisWindow, Start: 1e-3, Stop: 2e-3}, "V(out)")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	vout, err := ltspice.GetTrace[complex128](spectrum, "V(out)")
+func (sim *SimData) FFT(opts FFTOptions, names ...string) (*SimData, error) {
+	if sim.GetType() != TransientAnalysis {
+		return nil, fmt.Errorf("%w: FFT requires a transient analysis, got %s", ErrInvalidSimulationType, sim.GetType())
+	}
+	if opts.Window < RectangularWindow || opts.Window > KaiserWindow {
+		return nil, fmt.Errorf("fft: unknown window %d", opts.Window)
+	}
+	if opts.Points < 0 || opts.Beta < 0 {
+		return nil, errors.New("fft: points and beta must not be negative")
+	}
+	if len(names) == 0 {
+		for _, v := range sim.Meta.Variables[1:] {
+			names = append(names, v.Name)
+		}
+	}
+	selected, err := sim.SelectTraces(names...)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make([]Variable, len(selected.Meta.Variables))
+	copy(vars, selected.Meta.Variables)
+	vars[0] = Variable{order: 0, Name: "frequency", Typ: "frequency", size: realXAxisTraceByteSize}
+	for i := range vars {
+		vars[i].size = realXAxisTraceByteSize
+	}
+	out := selected.withVariables(vars)
+	out.Meta.SimType = ACAnalysis
+	out.Meta.PlotName = "FFT"
+	out.Meta.Flags = (sim.Meta.Flags & Stepped) | Complex | Forward | Double
+	out.xAxisLabel = "frequency"
+	out.data = nil
+	out.complexData = make(map[string][]complex128, len(vars))
+	out.steps = &steps{count: sim.steps.count, offsets: make([]int, 0, sim.steps.count)}
+
+	for s := 0; s < sim.steps.count; s++ {
+		x := sim.GetXAxis(s)
+		n := opts.Points
+		if n == 0 {
+			n = len(x)
+		}
+		n = nextPowerOfTwo(n)
+		grid, err := fftGrid(x, n, opts)
+		if err != nil {
+			return nil, fmt.Errorf("fft step %d: %w", s, err)
+		}
+		window := windowCoefficients(opts.Window, n, opts.Beta)
+		dt := grid[1] - grid[0]
+
+		out.steps.offsets = append(out.steps.offsets, len(out.complexData["frequency"]))
+		for k := 0; k <= n/2; k++ {
+			out.complexData["frequency"] = append(out.complexData["frequency"], complex(float64(k)/(float64(n)*dt), 0))
+		}
+		start, end := sim.stepBounds(s)
+		for _, v := range vars[1:] {
+			samples := interpolate(x, sim.data[v.Name][start:end], grid, ResampleOptions{Method: opts.Method})
+			out.complexData[v.Name] = append(out.complexData[v.Name], spectrum(samples, window)...)
+		}
+	}
+	out.Meta.NoPoints = len(out.complexData["frequency"])
+	out.stepPoints = out.Meta.NoPoints / out.steps.count
+	return out, nil
+}
+
+// fftGrid returns n uniformly spaced sample times covering the window of the x-axis x. The grid excludes the
+// end of the window, so a window spanning an integer number of periods is sampled coherently.
+func fftGrid(x []float64, n int, opts FFTOptions) ([]float64, error) {
+	if len(x) < 2 {
+		return nil, errors.New("at least two points are required")
+	}
+	lo, hi, err := xWindow(x, opts.Start, opts.Stop)
+	if err != nil {
+		return nil, err
+	}
+	grid := make([]float64, n)
+	for i := range grid {
+		grid[i] = lo + (hi-lo)*float64(i)/float64(n)
+	}
+	return grid, nil
+}
+
+// spectrum returns the bins 0 to n/2 of the windowed FFT of samples, scaled to amplitudes.
+func spectrum(samples, window []float64) []complex128 {
+	n := len(samples)
+	buf := make([]complex128, n)
+	var gain float64
+	for i := range samples {
+		buf[i] = complex(samples[i]*window[i], 0)
+		gain += window[i]
+	}
+	fftRadix2(buf)
+	out := make([]complex128, n/2+1)
+	for k := range out {
+		scale := 2 / gain
+		if k == 0 || k == n/2 {
+			scale = 1 / gain
+		}
+		out[k] = buf[k] * complex(scale, 0)
+	}
+	return out
+}
+
+// fftRadix2 computes the discrete Fourier transform of a in place. len(a) must be a power of two.
+func fftRadix2(a []complex128) {
+	n := len(a)
+	if n < 2 {
+		return
+	}
+	shift := 64 - bits.TrailingZeros(uint(n))
+	for i := range a {
+		if j := int(bits.Reverse64(uint64(i)) >> shift); j > i {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				u, t := a[start+k], w*a[start+k+size/2]
+				a[start+k], a[start+k+size/2] = u+t, u-t
+				w *= step
+			}
+		}
+	}
+}
+
+func nextPowerOfTwo(n int) int {
+	p := 2
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+// windowCoefficients returns the periodic window of length n.
+func windowCoefficients(w Window, n int, beta float64) []float64 {
+	cosineSum := func(a ...float64) []float64 {
+		out := make([]float64, n)
+		for i := range out {
+			sign := 1.0
+			for k, ak := range a {
+				out[i] += sign * ak * math.Cos(2*math.Pi*float64(k*i)/float64(n))
+				sign = -sign
+			}
+		}
+		return out
+	}
+
+	switch w {
+	case HannWindow:
+		return cosineSum(0.5, 0.5)
+	case BlackmanHarrisWindow:
+		return cosineSum(0.35875, 0.48829, 0.14128, 0.01168)
+	case FlatTopWindow:
+		return cosineSum(0.21557895, 0.41663158, 0.277263158, 0.083578947, 0.006947368)
+	case KaiserWindow:
+		if beta == 0 {
+			beta = defaultKaiserBeta
+		}
+		out := make([]float64, n)
+		for i := range out {
+			r := 2*float64(i)/float64(n) - 1
+			out[i] = besselI0(beta*math.Sqrt(1-r*r)) / besselI0(beta)
+		}
+		return out
+	default:
+		return cosineSum(1)
+	}
+}
+
+// besselI0 is the modified Bessel function of the first kind of order zero.
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1; k < 500; k++ {
+		term *= (x / (2 * float64(k))) * (x / (2 * float64(k)))
+		sum += term
+		if term < sum*1e-17 {
+			break
+		}
+	}
+	return sum
+}
@@ -0,0 +1,132 @@
+package ltspice
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// toneSim returns a transient analysis of 0.5 + sin(2π 1kHz t) + 0.1 sin(2π 2kHz t) on a non-uniform
+// time axis covering 10 periods.
+func toneSim(t *testing.T) *SimData {
+	rng := rand.New(rand.NewSource(2))
+	var x, y []float64
+	for tm := 0.0; tm < 10e-3; tm += 2e-6 + 4e-6*rng.Float64() {
+		x = append(x, tm)
+		y = append(y, 0.5+math.Sin(2*math.Pi*1e3*tm)+0.1*math.Sin(2*math.Pi*2e3*tm))
+	}
+	x = append(x, 10e-3)
+	y = append(y, 0.5)
+	sim, err := newTableSim(TransientAnalysis, "tones", []string{"time", "V(out)"}, [][]float64{x, y}, nil)
+	require.NoError(t, err)
+	return sim
+}
+
+func TestFFT(t *testing.T) {
+	sim := toneSim(t)
+	for _, w := range []Window{RectangularWindow, HannWindow, BlackmanHarrisWindow, FlatTopWindow, KaiserWindow} {
+		t.Run(w.String(), func(t *testing.T) {
+			spectrum, err := sim.FFT(FFTOptions{Window: w, Points: 1000, Method: CubicInterpolation})
+			require.NoError(t, err)
+			assert.Equal(t, ACAnalysis, spectrum.GetType())
+			assert.True(t, spectrum.Meta.Flags.hasFlag(Complex))
+			assert.Equal(t, []string{"frequency", "V(out)"}, variableNames(spectrum))
+			assert.Equal(t, 513, spectrum.Meta.NoPoints)
+
+			f := spectrum.GetXAxis()
+			assert.Equal(t, 0.0, f[0])
+			assert.InDelta(t, 100, f[1], 1e-9)
+			assert.InDelta(t, 51.2e3, f[512], 1e-6)
+
+			vout, err := GetTrace[complex128](spectrum, "V(out)")
+			require.NoError(t, err)
+			bins := vout.GetSignal()
+			assert.InDelta(t, 0.5, cmplx.Abs(bins[0]), 1e-3)
+			assert.InDelta(t, 1, cmplx.Abs(bins[10]), 1e-3)
+			assert.InDelta(t, 0.1, cmplx.Abs(bins[20]), 1e-3)
+			// sine starts at zero phase, i.e. -90° relative to a cosine
+			assert.InDelta(t, -math.Pi/2, cmplx.Phase(bins[10]), 1e-2)
+			assert.Less(t, cmplx.Abs(bins[100]), 1e-3)
+		})
+	}
+}
+
+func TestFFTWindow(t *testing.T) {
+	sim := toneSim(t)
+	// 5 periods from 2 ms on
+	spectrum, err := sim.FFT(FFTOptions{Window: HannWindow, Start: 2e-3, Stop: 7e-3, Points: 512, Method: CubicInterpolation}, "V(out)")
+	require.NoError(t, err)
+	assert.InDelta(t, 200, spectrum.GetXAxis()[1], 1e-9)
+	assert.InDelta(t, 1, cmplx.Abs(spectrum.complexData["V(out)"][5]), 1e-3)
+
+	// the last 5 periods, the window ends at the last point
+	spectrum, err = sim.FFT(FFTOptions{Start: 5e-3, Points: 512, Method: CubicInterpolation}, "V(out)")
+	require.NoError(t, err)
+	assert.InDelta(t, 200, spectrum.GetXAxis()[1], 1e-9)
+	assert.InDelta(t, 1, cmplx.Abs(spectrum.complexData["V(out)"][5]), 1e-3)
+
+	_, err = sim.FFT(FFTOptions{Start: 1, Stop: 2})
+	assert.Error(t, err)
+	_, err = sim.FFT(FFTOptions{Start: 1})
+	assert.Error(t, err)
+	_, err = sim.FFT(FFTOptions{Window: Window(9)})
+	assert.Error(t, err)
+	_, err = sim.FFT(FFTOptions{}, "V(missing)")
+	assert.ErrorIs(t, err, ErrTraceDoesNotExist)
+
+	ac, err := Parse("testdata/simulations/ac/low-pass/low-pass-filter.raw")
+	require.NoError(t, err)
+	_, err = ac.FFT(FFTOptions{})
+	assert.ErrorIs(t, err, ErrInvalidSimulationType)
+}
+
+func TestFFTStepped(t *testing.T) {
+	sim, err := Parse("testdata/simulations/stepped/rc/rc.raw")
+	require.NoError(t, err)
+	require.Greater(t, sim.GetSteps(), 1)
+
+	spectrum, err := sim.FFT(FFTOptions{Points: 256})
+	require.NoError(t, err)
+	assert.Equal(t, sim.GetSteps(), spectrum.GetSteps())
+	assert.True(t, spectrum.Meta.Flags.hasFlag(Stepped))
+	assert.Equal(t, 129*sim.GetSteps(), spectrum.Meta.NoPoints)
+	assert.Len(t, spectrum.GetXAxis(sim.GetSteps()-1), 129)
+	assert.Len(t, spectrum.GetVariables(), len(sim.GetVariables()))
+}
+
+func TestFFTRadix2(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	in := make([]complex128, 16)
+	for i := range in {
+		in[i] = complex(rng.Float64(), rng.Float64())
+	}
+	got := append([]complex128(nil), in...)
+	fftRadix2(got)
+	for k := range in {
+		var want complex128
+		for n := range in {
+			want += in[n] * cmplx.Exp(complex(0, -2*math.Pi*float64(k*n)/16))
+		}
+		assert.InDelta(t, 0, cmplx.Abs(got[k]-want), 1e-12)
+	}
+}
+
+func TestWindowCoefficients(t *testing.T) {
+	for _, w := range []Window{RectangularWindow, HannWindow, BlackmanHarrisWindow, FlatTopWindow, KaiserWindow} {
+		c := windowCoefficients(w, 64, 0)
+		require.Len(t, c, 64)
+		assert.InDelta(t, 1, c[32], 1e-8, w.String())
+		// periodic windows are symmetric around n/2
+		for i := 1; i < 32; i++ {
+			assert.InDelta(t, c[32-i], c[32+i], 1e-12, w.String())
+		}
+	}
+	assert.InDelta(t, 0, windowCoefficients(HannWindow, 64, 0)[0], 1e-12)
+	assert.InDelta(t, 11.301921952136330, besselI0(4), 1e-12)
+	assert.Equal(t, 1024, nextPowerOfTwo(1000))
+	assert.Equal(t, 2, nextPowerOfTwo(1))
+}
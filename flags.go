@@ -14,6 +14,9 @@ const (
 	Log
 	Stepped
 	FastAccess
+	// Double indicates that all variables are stored in double precision, as written by ngspice
+	// or by LTSpice with ".options numdgt" above 6.
+	Double
 )
 
 var flagLookup = map[string]Flags{
@@ -23,6 +26,7 @@ var flagLookup = map[string]Flags{
 	"log":        Log,
 	"stepped":    Stepped,
 	"fastaccess": FastAccess,
+	"double":     Double,
 }
 
 func parseFlags(flagStrings ...string) Flags {
@@ -67,5 +71,8 @@ func (f Flags) String() string {
 	if f&FastAccess != 0 {
 		flagStrings = append(flagStrings, "fastaccess")
 	}
+	if f&Double != 0 {
+		flagStrings = append(flagStrings, "double")
+	}
 	return strings.Join(flagStrings, "|")
 }
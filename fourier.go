@@ -0,0 +1,209 @@
+package ltspice
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FourierAnalysis is the result of a .four statement for one trace and step.
+type FourierAnalysis struct {
+	Trace string
+	// Step is the index of the step the analysis belongs to.
+	Step int
+	// Periods is the number of periods at the end of the simulation the analysis is performed on.
+	Periods int
+	DC      float64
+	// Harmonics starts with the fundamental.
+	Harmonics []Harmonic
+	// THD is the total harmonic distortion in percent.
+	THD float64
+	// THD2 is the second distortion value newer LTSpice versions print in parentheses, NaN if absent.
+	THD2 float64
+}
+
+// Harmonic is a row of the table of a Fourier analysis. Phases are in degrees.
+type Harmonic struct {
+	Number              int
+	Frequency           float64
+	Component           float64
+	NormalizedComponent float64
+	Phase               float64
+	NormalizedPhase     float64
+}
+
+// Fourier returns the results of the .four statements for the given trace (case-insensitive), one per step.
+func (l *LogFile) Fourier(trace string) []*FourierAnalysis {
+	var out []*FourierAnalysis
+	for _, f := range l.FourierAnalyses {
+		if strings.EqualFold(f.Trace, trace) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// parseFourier parses the block LTSpice writes for a .four statement:
+//
+//	Fourier components of V(out)
+//	DC component:-2.42788e-005
+//
+//	Harmonic	Frequency	 Fourier 	Normalized	 Phase  	Normalized
+//	 Number 	  [Hz]   	Component	 Component	[degree]	Phase [deg]
+//	    1   	1.000e+03	9.999e-01	1.000e+00	   -0.01°	    0.00°
+//	    2   	2.000e+03	1.142e-05	1.142e-05	   86.92°	   86.94°
+//	Total Harmonic Distortion: 0.001383%(0.001412%)
+//
+// It returns the analysis and the number of lines consumed. A block which ends before the total harmonic
+// distortion is an error.
+func parseFourier(lines []string) (*FourierAnalysis, int, error) {
+	f := &FourierAnalysis{
+		Trace: strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(lines[0]), "Fourier components of")),
+		THD:   math.NaN(),
+		THD2:  math.NaN(),
+	}
+	// the table ends with the total harmonic distortion, a blank or any other line ends the block early
+	unclosed := fmt.Errorf("%w: Fourier components of %s end without the total harmonic distortion", ErrParsingError, f.Trace)
+	n := 1
+	for ; n < len(lines); n++ {
+		line := strings.TrimSpace(lines[n])
+		switch {
+		case line == "" && len(f.Harmonics) == 0:
+			// blank line between the DC component and the table
+		case strings.HasPrefix(line, "Harmonic"), strings.HasPrefix(line, "Number"):
+			// table header
+		case strings.HasPrefix(line, "DC component:"):
+			dc, err := strconv.ParseFloat(extractHeaderValue(line), 64)
+			if err != nil {
+				return nil, 0, fmt.Errorf("%w: DC component of %s: %v", ErrParsingError, f.Trace, err)
+			}
+			f.DC = dc
+		case strings.HasPrefix(line, "Total Harmonic Distortion:"):
+			value := strings.TrimSuffix(extractHeaderValue(line), ")")
+			thd, thd2, _ := strings.Cut(value, "(")
+			f.THD = parsePercent(thd)
+			if thd2 != "" {
+				f.THD2 = parsePercent(thd2)
+			}
+			return f, n + 1, nil
+		default:
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				return nil, 0, unclosed
+			}
+			number, err := strconv.Atoi(fields[0])
+			if err != nil {
+				return nil, 0, fmt.Errorf("%w at %q", unclosed, line)
+			}
+			if len(fields) < 6 {
+				return nil, 0, fmt.Errorf("%w: harmonic %d of %s: %q", ErrParsingError, number, f.Trace, line)
+			}
+			h := Harmonic{Number: number}
+			values := []*float64{&h.Frequency, &h.Component, &h.NormalizedComponent, &h.Phase, &h.NormalizedPhase}
+			for i, v := range values {
+				*v, err = strconv.ParseFloat(strings.TrimRight(fields[i+1], "°"), 64)
+				if err != nil {
+					return nil, 0, fmt.Errorf("%w: harmonic %d of %s: %v", ErrParsingError, number, f.Trace, err)
+				}
+			}
+			f.Harmonics = append(f.Harmonics, h)
+		}
+	}
+	return nil, 0, unclosed
+}
+
+func parsePercent(s string) float64 {
+	v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "%")), 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return v
+}
+
+// fourierSamplesPerPeriod is the number of uniform samples per period Fourier integrates over.
+const fourierSamplesPerPeriod = 1024
+
+// Fourier performs the Fourier analysis of LTSpice's .four statement on a trace of a transient analysis: the
+// DC component and the first harmonics of the fundamental frequency freq are computed over the last
+// periods periods of the simulation. For stepped simulations a step index selects the step, the first step
+// is used if none is given.
+//
+// Like in LTSpice, phases are relative to a sine and to the absolute simulation time, the normalized phase
+// is relative to the fundamental and THD is in percent.
+//
+// Example usage:
+//
+//	four, err := simData.Fourier("V(out)", 1e3, 9, 1)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("THD: %.4f%%\n", four.THD)
+func (sim *SimData) Fourier(name string, freq float64, harmonics, periods int, step ...int) (*FourierAnalysis, error) {
+	if sim.GetType() != TransientAnalysis {
+		return nil, fmt.Errorf("%w: Fourier analysis requires a transient analysis, got %s", ErrInvalidSimulationType, sim.GetType())
+	}
+	if freq <= 0 || harmonics <= 0 || periods <= 0 {
+		return nil, errors.New("fourier: frequency, harmonics and periods must be positive")
+	}
+	trace, err := GetTrace[float64](sim, name)
+	if err != nil {
+		return nil, err
+	}
+	s := 0
+	if len(step) > 0 {
+		s = step[0]
+	}
+	x := sim.GetXAxis(step...)
+	y := trace.GetSignal(step...)
+	if len(x) < 2 {
+		return nil, fmt.Errorf("fourier: step %d of %s has less than two points", s, name)
+	}
+	lo, hi := xRange(x)
+	span := float64(periods) / freq
+	if hi-lo < span*(1-1e-9) {
+		return nil, fmt.Errorf("fourier: %d periods of %g Hz are longer than the simulation", periods, freq)
+	}
+
+	n := max(fourierSamplesPerPeriod, 32*harmonics) * periods
+	grid := make([]float64, n)
+	for i := range grid {
+		grid[i] = hi - span + span*float64(i)/float64(n)
+	}
+	samples := interpolate(x, y, grid, ResampleOptions{Method: CubicInterpolation})
+
+	f := &FourierAnalysis{Trace: name, Step: s, Periods: periods, THD2: math.NaN()}
+	for _, v := range samples {
+		f.DC += v
+	}
+	f.DC /= float64(n)
+
+	var distortion float64
+	for h := 1; h <= harmonics; h++ {
+		var a, b float64
+		w := 2 * math.Pi * float64(h) * freq
+		for i, v := range samples {
+			a += v * math.Cos(w*grid[i])
+			b += v * math.Sin(w*grid[i])
+		}
+		a, b = 2*a/float64(n), 2*b/float64(n)
+		harmonic := Harmonic{
+			Number:    h,
+			Frequency: float64(h) * freq,
+			Component: math.Hypot(a, b),
+			// a cos(wt) + b sin(wt) = C sin(wt + phase)
+			Phase: math.Atan2(a, b) * 180 / math.Pi,
+		}
+		fundamental := harmonic
+		if h > 1 {
+			fundamental = f.Harmonics[0]
+			distortion += harmonic.Component * harmonic.Component
+		}
+		harmonic.NormalizedComponent = harmonic.Component / fundamental.Component
+		harmonic.NormalizedPhase = harmonic.Phase - fundamental.Phase
+		f.Harmonics = append(f.Harmonics, harmonic)
+	}
+	f.THD = 100 * math.Sqrt(distortion) / f.Harmonics[0].Component
+	return f, nil
+}
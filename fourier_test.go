@@ -0,0 +1,128 @@
+package ltspice
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLogFourier(t *testing.T) {
+	log := strings.Join([]string{
+		"Circuit: * amp.asc",
+		".step a=1",
+		".step a=2",
+		"N-Period=2",
+		"Fourier components of V(out)",
+		"DC component:-2.42788e-005",
+		"",
+		"Harmonic\tFrequency\t Fourier \tNormalized\t Phase  \tNormalized",
+		" Number \t  [Hz]   \tComponent\t Component\t[degree]\tPhase [deg]",
+		"    1   \t1.000e+03\t9.999e-01\t1.000e+00\t   -0.01°\t    0.00°",
+		"    2   \t2.000e+03\t1.142e-05\t1.142e-05\t   86.92°\t   86.94°",
+		"    3   \t3.000e+03\t2.000e-05\t2.000e-05\t  -12.50°\t  -12.49°",
+		"Total Harmonic Distortion: 0.002303%(0.002412%)",
+		"",
+		"Fourier components of V(out)",
+		"DC component:1e-3",
+		"Harmonic\tFrequency\t Fourier \tNormalized\t Phase  \tNormalized",
+		" Number \t  [Hz]   \tComponent\t Component\t[degree]\tPhase [deg]",
+		"    1   \t1.000e+03\t1.999e+00\t1.000e+00\t    0.01°\t    0.00°",
+		"Total Harmonic Distortion: 0.000000%",
+		"",
+		"Total elapsed time: 0.1 seconds.",
+	}, "\n")
+
+	l, err := ParseLogFromReader(strings.NewReader(log))
+	require.NoError(t, err)
+	require.Len(t, l.FourierAnalyses, 2)
+	assert.Empty(t, l.Measurements)
+
+	four := l.Fourier("v(out)")
+	require.Len(t, four, 2)
+	f := four[0]
+	assert.Equal(t, "V(out)", f.Trace)
+	assert.Equal(t, 0, f.Step)
+	assert.Equal(t, 2, f.Periods)
+	assert.Equal(t, -2.42788e-5, f.DC)
+	require.Len(t, f.Harmonics, 3)
+	assert.Equal(t, Harmonic{Number: 2, Frequency: 2e3, Component: 1.142e-5, NormalizedComponent: 1.142e-5, Phase: 86.92, NormalizedPhase: 86.94}, f.Harmonics[1])
+	assert.Equal(t, 0.002303, f.THD)
+	assert.Equal(t, 0.002412, f.THD2)
+
+	assert.Equal(t, 1, four[1].Step)
+	assert.Len(t, four[1].Harmonics, 1)
+	assert.Equal(t, 0.0, four[1].THD)
+	assert.True(t, math.IsNaN(four[1].THD2))
+
+	_, err = ParseLogFromReader(strings.NewReader("Fourier components of V(a)\n    1\t1e3\t1\t1\t0°\n"))
+	assert.ErrorIs(t, err, ErrParsingError)
+
+	// a block without the total harmonic distortion does not swallow the rest of the log
+	unclosed := []string{
+		"Fourier components of V(out)",
+		"DC component:0",
+		"",
+		"Harmonic\tFrequency\t Fourier \tNormalized\t Phase  \tNormalized",
+		" Number \t  [Hz]   \tComponent\t Component\t[degree]\tPhase [deg]",
+		"    1   \t1.000e+03\t1.000e+00\t1.000e+00\t    0.00°\t    0.00°",
+	}
+	for _, end := range []string{"", "vmax: MAX(v(out))=1.5 FROM 0 TO 0.001", "Fourier components of V(in)"} {
+		_, err = ParseLogFromReader(strings.NewReader(strings.Join(append(unclosed, end, "vmax: MAX(v(out))=1.5"), "\n")))
+		assert.ErrorIs(t, err, ErrParsingError, end)
+	}
+	_, err = ParseLogFromReader(strings.NewReader(strings.Join(unclosed, "\n")))
+	assert.ErrorIs(t, err, ErrParsingError)
+}
+
+func TestFourier(t *testing.T) {
+	sim := distortedSim(t, 1e-3)
+	f, err := sim.Fourier("V(out)", 1e3, 9, 4)
+	require.NoError(t, err)
+	assert.Equal(t, 4, f.Periods)
+	assert.InDelta(t, 0, f.DC, 1e-9)
+	require.Len(t, f.Harmonics, 9)
+
+	assert.InDelta(t, 1, f.Harmonics[0].Component, 1e-6)
+	assert.InDelta(t, 0, f.Harmonics[0].Phase, 1e-4)
+	assert.InDelta(t, 0.01, f.Harmonics[1].NormalizedComponent, 1e-6)
+	assert.InDelta(t, 2e3, f.Harmonics[1].Frequency, 1e-9)
+	assert.InDelta(t, 0.005, f.Harmonics[2].Component, 1e-6)
+	assert.InDelta(t, 0, f.Harmonics[3].Component, 1e-6)
+	assert.InDelta(t, 100*math.Sqrt(0.01*0.01+0.005*0.005), f.THD, 1e-4)
+	assert.True(t, math.IsNaN(f.THD2))
+
+	// the noisy second step
+	f, err = sim.Fourier("V(out)", 1e3, 3, 20, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.Step)
+	assert.InDelta(t, 1, f.Harmonics[0].Component, 1e-3)
+	assert.InDelta(t, 1.118, f.THD, 0.02)
+}
+
+func TestFourierPhase(t *testing.T) {
+	var x, y []float64
+	for i := 0; i <= 1000; i++ {
+		tm := 3e-3 * float64(i) / 1000
+		x = append(x, tm)
+		y = append(y, 2+math.Cos(2*math.Pi*1e3*tm)+0.1*math.Sin(2*math.Pi*2e3*tm+math.Pi/4))
+	}
+	sim, err := newTableSim(TransientAnalysis, "phase", []string{"time", "V(out)"}, [][]float64{x, y}, nil)
+	require.NoError(t, err)
+	f, err := sim.Fourier("V(out)", 1e3, 2, 1)
+	require.NoError(t, err)
+	assert.InDelta(t, 2, f.DC, 1e-6)
+	assert.InDelta(t, 90, f.Harmonics[0].Phase, 1e-3)
+	assert.InDelta(t, 45, f.Harmonics[1].Phase, 1e-3)
+	assert.InDelta(t, -45, f.Harmonics[1].NormalizedPhase, 1e-3)
+	assert.InDelta(t, 10, f.THD, 1e-4)
+
+	_, err = sim.Fourier("V(out)", 100, 2, 1)
+	assert.Error(t, err)
+	_, err = sim.Fourier("V(out)", 1e3, 0, 1)
+	assert.Error(t, err)
+	_, err = sim.Fourier("V(missing)", 1e3, 2, 1)
+	assert.ErrorIs(t, err, ErrTraceDoesNotExist)
+}
@@ -2,7 +2,6 @@ package ltspice
 
 import (
 	"fmt"
-	"io"
 	"log"
 	"strconv"
 	"strings"
@@ -22,6 +21,8 @@ const (
 	headerCommand         = "Command"
 	headerVariables       = "Variables"
 	headerBackannotation  = "Backannotation"
+	headerOutput          = "Output"
+	headerSteps           = "No. Steps"
 )
 
 const (
@@ -37,7 +38,7 @@ type Variable struct {
 	size  int    // the size of a signle data point in bytes
 }
 
-func parseHeaderLine(r io.Reader, metadata *MetaData, line string) error {
+func parseHeaderLine(r *headerReader, metadata *MetaData, line string) error {
 	lineType := strings.SplitN(line, ":", 2)[0]
 	switch lineType {
 
@@ -46,9 +47,10 @@ func parseHeaderLine(r io.Reader, metadata *MetaData, line string) error {
 
 	case headerPlotName:
 		sim := extractHeaderValue(line)
+		metadata.PlotName = sim
 		simType, err := simTypeFromString(sim)
 		if err != nil {
-			return ErrInvalidSimulationType
+			return fmt.Errorf("%w: %s", ErrInvalidSimulationType, sim)
 		}
 		metadata.SimType = simType
 
@@ -65,7 +67,7 @@ func parseHeaderLine(r io.Reader, metadata *MetaData, line string) error {
 		}
 
 	case headerDate:
-		t, err := time.Parse(dateHeaderLayout, line)
+		t, err := time.Parse(dateHeaderLayout, strings.Join(strings.Fields(line), " "))
 		if err != nil {
 			log.Println("Error parsing date:", err)
 		} else {
@@ -94,7 +96,7 @@ func parseHeaderLine(r io.Reader, metadata *MetaData, line string) error {
 		}
 		metadata.Variables = make([]Variable, metadata.NoVariables)
 		for i := 0; i < metadata.NoVariables; i++ {
-			l, err := readLineUTF16(r)
+			l, err := r.readLine()
 			if err != nil {
 				return fmt.Errorf("%w: %v", ErrInvalidSimulationHeader, err)
 			}
@@ -111,7 +113,19 @@ func parseHeaderLine(r io.Reader, metadata *MetaData, line string) error {
 		}
 	case headerFlags:
 		flagStr := extractHeaderValue(line)
-		metadata.Flags = parseFlags(strings.Fields(flagStr)...)
+		// keep the stepped flag in case the number of steps was given before the flags
+		metadata.Flags = parseFlags(strings.Fields(flagStr)...) | metadata.Flags&Stepped
+	case headerSteps:
+		// written by Qspice, which does not set the stepped flag
+		num, err := strconv.Atoi(extractHeaderValue(line))
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidSimulationHeader, "failed to parse the number of steps from the header")
+		}
+		if num > 1 {
+			metadata.Flags.setFlag(Stepped)
+		}
+	case headerOutput:
+		metadata.Output = extractHeaderValue(line)
 	case headerBackannotation:
 		return nil
 	default:
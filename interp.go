@@ -0,0 +1,80 @@
+package ltspice
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// interpLinear evaluates the piecewise linear interpolation of (x, y) at xq.
+// x must be monotonic (increasing or decreasing), duplicate x values are allowed.
+// Points outside of the range of x are clamped to the first or last value.
+func interpLinear[T float64 | complex128](x []float64, y []T, xq float64) T {
+	n := len(x)
+	if n == 0 {
+		return 0
+	}
+	if n == 1 {
+		return y[0]
+	}
+	decreasing := x[0] > x[n-1]
+	// i is the index of the first point with x[i] > xq (< xq for decreasing x)
+	i := sort.Search(n, func(i int) bool {
+		if decreasing {
+			return x[i] < xq
+		}
+		return x[i] > xq
+	})
+	switch {
+	case i == 0:
+		return y[0]
+	case i == n:
+		return y[n-1]
+	}
+	x0, x1 := x[i-1], x[i]
+	if x1 == x0 {
+		return y[i]
+	}
+	return lerp(y[i-1], y[i], (xq-x0)/(x1-x0))
+}
+
+// lerp returns a + t * (b - a).
+func lerp[T float64 | complex128](a, b T, t float64) T {
+	switch av := any(a).(type) {
+	case float64:
+		return any(av + t*(any(b).(float64)-av)).(T)
+	case complex128:
+		return any(av + complex(t, 0)*(any(b).(complex128)-av)).(T)
+	}
+	return a
+}
+
+// xRange returns the minimum and maximum of a monotonic axis.
+func xRange(x []float64) (float64, float64) {
+	if len(x) == 0 {
+		return 0, 0
+	}
+	if x[0] > x[len(x)-1] {
+		return x[len(x)-1], x[0]
+	}
+	return x[0], x[len(x)-1]
+}
+
+// xWindow returns the part of the range of the axis x between start and stop. Zero values select the first
+// and last point, a window which does not overlap the axis is an error.
+func xWindow(x []float64, start, stop float64) (float64, float64, error) {
+	lo, hi := xRange(x)
+	if start == 0 && stop == 0 {
+		return lo, hi, nil
+	}
+	if start != 0 {
+		lo = math.Max(lo, start)
+	}
+	if stop != 0 {
+		hi = math.Min(hi, stop)
+	}
+	if hi <= lo {
+		return 0, 0, fmt.Errorf("window [%g, %g] does not overlap the x-axis", start, stop)
+	}
+	return lo, hi, nil
+}
@@ -0,0 +1,64 @@
+package ltspice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpLinear(t *testing.T) {
+	x := []float64{0, 1, 1, 3}
+	y := []float64{0, 10, 20, 40}
+	tests := []struct {
+		name string
+		xq   float64
+		want float64
+	}{
+		{name: "first point", xq: 0, want: 0},
+		{name: "between", xq: 0.5, want: 5},
+		{name: "duplicate x takes last", xq: 1, want: 20},
+		{name: "after duplicate", xq: 2, want: 30},
+		{name: "below range", xq: -1, want: 0},
+		{name: "above range", xq: 4, want: 40},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, interpLinear(x, y, tt.xq), 1e-12)
+		})
+	}
+
+	// decreasing axis, e.g. a DC sweep from high to low
+	assert.InDelta(t, 15.0, interpLinear([]float64{3, 2, 1}, []float64{30, 20, 10}, 1.5), 1e-12)
+
+	c := interpLinear([]float64{0, 2}, []complex128{0, complex(2, -4)}, 1)
+	assert.Equal(t, complex(1, -2), c)
+}
+
+func TestXWindow(t *testing.T) {
+	x := []float64{0, 1, 2, 3}
+	tests := []struct {
+		name        string
+		start, stop float64
+		lo, hi      float64
+		wantErr     bool
+	}{
+		{name: "whole axis", lo: 0, hi: 3},
+		{name: "start only", start: 1.5, lo: 1.5, hi: 3},
+		{name: "stop only", stop: 2, lo: 0, hi: 2},
+		{name: "clipped", start: -1, stop: 5, lo: 0, hi: 3},
+		{name: "stop before start", start: 2, stop: 1, wantErr: true},
+		{name: "outside", start: 4, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lo, hi, err := xWindow(x, tt.start, tt.stop)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.lo, lo)
+			assert.Equal(t, tt.hi, hi)
+		})
+	}
+}
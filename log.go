@@ -0,0 +1,331 @@
+package ltspice
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"math/cmplx"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// LogFile holds the information LTSpice writes into the .log file of a simulation run.
+type LogFile struct {
+	// Circuit is the netlist or schematic the simulation was run on.
+	Circuit string
+	// Date is the date the simulation was run on, if reported.
+	Date time.Time
+	// ElapsedTime is the reported total elapsed time.
+	ElapsedTime time.Duration
+	// Steps holds the parameter values of each step of a stepped simulation, e.g. ".step r=1k c=10n".
+	Steps []StepParams
+	// Measurements holds the results of all .meas statements in the order they appear.
+	Measurements []*Measurement
+	// FourierAnalyses holds the results of all .four statements in the order they appear.
+	FourierAnalyses []*FourierAnalysis
+	// Warnings holds the warnings reported by LTSpice.
+	Warnings []string
+	// Errors holds error messages, e.g. convergence failures.
+	Errors []string
+	// Stats holds the "key = value" statistics such as tnom, method and totiter.
+	Stats map[string]string
+}
+
+// StepParams holds the parameter values of a single step in the order they appear.
+type StepParams []StepParam
+
+// StepParam is a single stepped parameter. Value is the value as printed by LTSpice (e.g. "1k").
+type StepParam struct {
+	Name  string
+	Value string
+}
+
+// Get returns the value of the parameter name.
+func (p StepParams) Get(name string) (string, bool) {
+	for _, param := range p {
+		if strings.EqualFold(param.Name, name) {
+			return param.Value, true
+		}
+	}
+	return "", false
+}
+
+// Measurement is the result of a .meas statement.
+type Measurement struct {
+	Name string
+	// Expr is the measured expression as printed by LTSpice, e.g. MAX(v(out)).
+	Expr string
+	// Values holds the result of every step. Failed measurements are NaN.
+	// For complex results (AC analysis) Values holds the magnitude.
+	Values []float64
+	// Complex holds the results of measurements printed as complex numbers, nil otherwise.
+	Complex []complex128
+}
+
+// Measurement returns the measurement with the given name (case-insensitive).
+func (l *LogFile) Measurement(name string) (*Measurement, bool) {
+	for _, m := range l.Measurements {
+		if strings.EqualFold(m.Name, name) {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+var (
+	// name: expr=value [FROM a TO b | AT x]
+	measLine = regexp.MustCompile(`^([A-Za-z_][\w.]*):\s*(.*?)=\s*(\S+)(?:\s+(?:FROM|AT|at)\s.*)?$`)
+	// name=value [FROM a TO b | AT x]
+	measShortLine = regexp.MustCompile(`^([A-Za-z_][\w.]*)=\s*(\S+)(?:\s+(?:FROM|AT|at)\s.*)?$`)
+	statLine      = regexp.MustCompile(`^([A-Za-z][\w ]*?)\s+=\s+(.+)$`)
+	measFailed    = regexp.MustCompile(`^Measurement "(.+)" FAIL`)
+	// Error: ..., ERROR ... or Fatal Error: ..., but not a measurement such as error_max: ...
+	errorLine   = regexp.MustCompile(`(?i)^(?:fatal\s+)?(?:error|fatal)(?:$|[^\w.])`)
+	elapsedLine = regexp.MustCompile(`^Total elapsed time:\s*([\d.eE+-]+)\s*seconds`)
+)
+
+const logDateLayout = "Mon Jan 2 15:04:05 2006"
+
+// ParseLog reads and parses an LTSpice .log file. Both UTF-16 (LTSpice 24 and later) and
+// ASCII encoded log files are supported.
+func ParseLog(fileName string) (*LogFile, error) {
+	b, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return ParseLogFromReader(bytes.NewReader(b))
+}
+
+// ParseLogFromReader parses an LTSpice log from the provided io.Reader.
+func ParseLogFromReader(r io.Reader) (*LogFile, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	text := decodeLogText(b)
+
+	l := &LogFile{Stats: map[string]string{}}
+	periods := 1
+	// failures holds the failed attempts which are not followed by a successful one
+	var failures []string
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "Circuit:"):
+			l.Circuit = extractHeaderValue(line)
+		case strings.HasPrefix(line, "Date:"), strings.HasPrefix(line, "Start Time:"):
+			if t, err := time.Parse(logDateLayout, strings.Join(strings.Fields(extractHeaderValue(line)), " ")); err == nil {
+				l.Date = t
+			}
+		case strings.HasPrefix(line, "Warning:"), strings.HasPrefix(line, "WARNING:"):
+			l.Warnings = append(l.Warnings, extractHeaderValue(line))
+		case isLogError(line):
+			l.Errors = append(l.Errors, line)
+		case isLogFailure(line):
+			failures = append(failures, line)
+		case strings.Contains(strings.ToLower(line), "succeeded"):
+			// e.g. source stepping found the operating point after Gmin stepping failed
+			failures = nil
+		case strings.HasPrefix(line, ".step"):
+			l.Steps = append(l.Steps, parseStepParams(strings.TrimPrefix(line, ".step")))
+		case strings.HasPrefix(line, "N-Period="):
+			if n, err := strconv.Atoi(strings.TrimPrefix(line, "N-Period=")); err == nil {
+				periods = n
+			}
+		case strings.HasPrefix(line, "Fourier components of"):
+			f, n, err := parseFourier(lines[i:])
+			if err != nil {
+				return nil, err
+			}
+			f.Periods = periods
+			f.Step = len(l.Fourier(f.Trace))
+			l.FourierAnalyses = append(l.FourierAnalyses, f)
+			i += n - 1
+		case measFailed.MatchString(line):
+			name := measFailed.FindStringSubmatch(line)[1]
+			l.Measurements = append(l.Measurements, newMeasurement(name, "", []string{"FAILED"}))
+		case strings.HasPrefix(line, "Measurement:"):
+			m, n, err := parseSteppedMeasurement(lines[i:])
+			if err != nil {
+				return nil, err
+			}
+			l.Measurements = append(l.Measurements, m)
+			i += n - 1
+		case elapsedLine.MatchString(line):
+			secs, _ := strconv.ParseFloat(elapsedLine.FindStringSubmatch(line)[1], 64)
+			l.ElapsedTime = time.Duration(secs * float64(time.Second))
+		case measLine.MatchString(line):
+			sm := measLine.FindStringSubmatch(line)
+			l.Measurements = append(l.Measurements, newMeasurement(sm[1], sm[2], []string{sm[3]}))
+		case measShortLine.MatchString(line):
+			sm := measShortLine.FindStringSubmatch(line)
+			l.Measurements = append(l.Measurements, newMeasurement(sm[1], "", []string{sm[2]}))
+		case statLine.MatchString(line):
+			sm := statLine.FindStringSubmatch(line)
+			l.Stats[sm[1]] = sm[2]
+		}
+	}
+	l.Errors = append(l.Errors, failures...)
+	return l, nil
+}
+
+// fatalMessages are parts of the messages LTSpice reports when it aborts a simulation.
+var fatalMessages = []string{
+	"time step too small",
+	"iteration limit reached",
+}
+
+// convergenceMessages are parts of the messages LTSpice reports when a simulation does not converge.
+var convergenceMessages = []string{
+	"time step too small",
+	"iteration limit reached",
+	"singular matrix",
+	"failed to find",
+	"stepping failed",
+	"did not converge",
+}
+
+// isLogError reports whether a line of the log is an error which aborts the simulation.
+func isLogError(line string) bool {
+	if errorLine.MatchString(line) {
+		return true
+	}
+	lower := strings.ToLower(line)
+	for _, m := range fatalMessages {
+		if strings.Contains(lower, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLogFailure reports whether a line of the log is a failed attempt, e.g. "Gmin stepping failed", which
+// is only an error if no later attempt succeeds.
+func isLogFailure(line string) bool {
+	return strings.Contains(strings.ToLower(line), "failed")
+}
+
+func isConvergenceMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, m := range convergenceMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeLogText decodes UTF-16LE log files (detected by their BOM or zero high bytes) and
+// passes through ASCII/UTF-8 log files.
+func decodeLogText(b []byte) string {
+	isUTF16 := len(b) >= 2 && b[0] == 0xFF && b[1] == 0xFE
+	if !isUTF16 && len(b) >= 4 && b[1] == 0 && b[3] == 0 {
+		isUTF16 = true
+	}
+	if !isUTF16 {
+		return strings.TrimPrefix(string(b), "\ufeff")
+	}
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = uint16(b[2*i]) | uint16(b[2*i+1])<<8
+	}
+	return strings.TrimPrefix(string(utf16.Decode(u)), "\ufeff")
+}
+
+func parseStepParams(s string) StepParams {
+	var params StepParams
+	for _, field := range strings.Fields(s) {
+		name, value, ok := strings.Cut(field, "=")
+		if !ok {
+			continue
+		}
+		params = append(params, StepParam{Name: name, Value: value})
+	}
+	return params
+}
+
+// parseSteppedMeasurement parses the table LTSpice writes for measurements of stepped simulations:
+//
+//	Measurement: vout_max
+//	  step	MAX(v(out))	FROM	TO
+//	     1	1.2345	0	0.001
+//	     2	1.3456	0	0.001
+//
+// It returns the measurement and the number of lines consumed.
+func parseSteppedMeasurement(lines []string) (*Measurement, int, error) {
+	name := extractHeaderValue(lines[0])
+	if len(lines) < 2 {
+		return nil, 0, fmt.Errorf("%w: measurement %s has no results", ErrParsingError, name)
+	}
+	header := strings.Split(strings.TrimSpace(lines[1]), "\t")
+	expr := ""
+	if len(header) > 1 {
+		expr = strings.TrimSpace(header[1])
+	}
+	var values []string
+	n := 2
+	for ; n < len(lines); n++ {
+		fields := strings.Fields(lines[n])
+		if len(fields) < 2 {
+			break
+		}
+		if _, err := strconv.Atoi(fields[0]); err != nil {
+			break
+		}
+		values = append(values, fields[1])
+	}
+	return newMeasurement(name, expr, values), n, nil
+}
+
+func newMeasurement(name, expr string, values []string) *Measurement {
+	m := &Measurement{Name: name, Expr: strings.TrimSpace(expr), Values: make([]float64, len(values))}
+	for i, v := range values {
+		if c, ok := parseLogComplex(v); ok {
+			if m.Complex == nil {
+				m.Complex = make([]complex128, len(values))
+			}
+			m.Complex[i] = c
+			m.Values[i] = cmplx.Abs(c)
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			f = math.NaN()
+		}
+		m.Values[i] = f
+	}
+	return m
+}
+
+// parseLogComplex parses complex numbers as printed by LTSpice, either in polar form
+// "(20.1dB,-45.3°)" or in cartesian form "(1.2,-3.4)".
+func parseLogComplex(s string) (complex128, bool) {
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return 0, false
+	}
+	a, b, ok := strings.Cut(s[1:len(s)-1], ",")
+	if !ok {
+		return 0, false
+	}
+	if strings.HasSuffix(a, "dB") {
+		mag, err1 := strconv.ParseFloat(strings.TrimSuffix(a, "dB"), 64)
+		deg, err2 := strconv.ParseFloat(strings.TrimRight(b, "°"), 64)
+		if err1 != nil || err2 != nil {
+			return 0, false
+		}
+		return cmplx.Rect(math.Pow(10, mag/20), deg*math.Pi/180), true
+	}
+	re, err1 := strconv.ParseFloat(a, 64)
+	im, err2 := strconv.ParseFloat(b, 64)
+	if err1 != nil || err2 != nil {
+		return 0, false
+	}
+	return complex(re, im), true
+}
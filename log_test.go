@@ -0,0 +1,129 @@
+package ltspice
+
+import (
+	"math"
+	"math/cmplx"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLog(t *testing.T) {
+	l, err := ParseLog("testdata/simulations/noise/noise.log")
+	require.NoError(t, err)
+
+	assert.Equal(t, `* Z:\home\ahmed\wine\ltspice\noise.asc`, l.Circuit)
+	assert.Len(t, l.Warnings, 4)
+	assert.Equal(t, time.Date(2023, time.August, 1, 21, 2, 15, 0, time.UTC), l.Date)
+	assert.Equal(t, 23*time.Millisecond, l.ElapsedTime)
+	assert.Equal(t, "trap", l.Stats["method"])
+	assert.Equal(t, "41", l.Stats["matrix size"])
+
+	require.Len(t, l.Measurements, 2)
+	m, ok := l.Measurement("TOTAL_OUTPUT_REFERED_RMS_NOISE")
+	require.True(t, ok)
+	assert.Equal(t, "INTEG(v(onoise))", m.Expr)
+	assert.Equal(t, []float64{2.0238e-005}, m.Values)
+	assert.Nil(t, m.Complex)
+}
+
+func TestParseLogUTF16(t *testing.T) {
+	l, err := ParseLog("testdata/simulations/trans/stepped/tran-stepped.logg")
+	require.NoError(t, err)
+
+	assert.Equal(t, "*", l.Circuit)
+	require.Len(t, l.Steps, 2)
+	v, ok := l.Steps[1].Get("x")
+	assert.True(t, ok)
+	assert.Equal(t, "30", v)
+	assert.Equal(t, 24*time.Millisecond, l.ElapsedTime)
+}
+
+func TestParseLogErrors(t *testing.T) {
+	// the operating point is found by source stepping after the other methods failed
+	recovered := strings.Join([]string{
+		"Direct Newton iteration failed to find .op point.  (Use \".option noopiter\" to skip.)",
+		"Starting Gmin stepping",
+		"Gmin stepping failed",
+		"Starting source stepping with srcstepmethod=0",
+		"Source stepping succeeded in finding the operating point.",
+		"Total elapsed time: 0.123 seconds.",
+	}, "\n")
+	l, err := ParseLogFromReader(strings.NewReader(recovered))
+	require.NoError(t, err)
+	assert.Empty(t, l.Errors)
+
+	failed := strings.Join([]string{
+		"Direct Newton iteration failed to find .op point.",
+		"Starting Gmin stepping",
+		"Gmin stepping failed",
+		"Starting source stepping with srcstepmethod=0",
+		"Source stepping failed",
+	}, "\n")
+	l, err = ParseLogFromReader(strings.NewReader(failed))
+	require.NoError(t, err)
+	assert.Len(t, l.Errors, 3)
+	assert.ErrorIs(t, simulationError(l, ErrSimulationFailed), ErrConvergence)
+
+	for _, line := range []string{"Analysis: Time step too small; time = 1.2e-05, timestep = 1.25e-19", "Iteration limit reached"} {
+		l, err = ParseLogFromReader(strings.NewReader(line))
+		require.NoError(t, err)
+		assert.Equal(t, []string{line}, l.Errors)
+		assert.ErrorIs(t, simulationError(l, ErrSimulationFailed), ErrConvergence)
+	}
+
+	// measurements whose names start like an error are measurements
+	l, err = ParseLogFromReader(strings.NewReader("error_max: MAX(v(err))=1.2 FROM 0 TO 0.001\nError: unknown node err2\nFatal Error: out of memory"))
+	require.NoError(t, err)
+	require.Len(t, l.Measurements, 1)
+	assert.Equal(t, "error_max", l.Measurements[0].Name)
+	assert.Equal(t, []float64{1.2}, l.Measurements[0].Values)
+	assert.Equal(t, []string{"Error: unknown node err2", "Fatal Error: out of memory"}, l.Errors)
+}
+
+func TestParseLogSteppedMeasurements(t *testing.T) {
+	log := strings.Join([]string{
+		".step r=1k c=10n",
+		".step r=2k c=10n",
+		"",
+		"Measurement: vmax",
+		"  step\tMAX(v(out))\tFROM\tTO",
+		"     1\t1.5\t0\t0.001",
+		"     2\t2.5\t0\t0.001",
+		"",
+		"Measurement: gain",
+		"  step\tv(out)/v(in)\tAT",
+		"     1\t(6.0206dB,-90°)\t1000",
+		"     2\t(1,1)\t1000",
+		"",
+		`Measurement "rise" FAIL'ed`,
+		"Direct Newton iteration failed to find .op point.",
+		"period=0.001 FROM 0 TO 0.01",
+	}, "\r\n")
+	l, err := ParseLogFromReader(strings.NewReader(log))
+	require.NoError(t, err)
+
+	require.Len(t, l.Steps, 2)
+	assert.Equal(t, StepParams{{Name: "r", Value: "2k"}, {Name: "c", Value: "10n"}}, l.Steps[1])
+
+	require.Len(t, l.Measurements, 4)
+	assert.Equal(t, "vmax", l.Measurements[0].Name)
+	assert.Equal(t, "MAX(v(out))", l.Measurements[0].Expr)
+	assert.Equal(t, []float64{1.5, 2.5}, l.Measurements[0].Values)
+
+	gain := l.Measurements[1]
+	require.Len(t, gain.Complex, 2)
+	assert.InDelta(t, 2, cmplx.Abs(gain.Complex[0]), 1e-4)
+	assert.InDelta(t, -math.Pi/2, cmplx.Phase(gain.Complex[0]), 1e-9)
+	assert.Equal(t, complex(1, 1), gain.Complex[1])
+	assert.InDelta(t, math.Sqrt2, gain.Values[1], 1e-12)
+
+	assert.Equal(t, "rise", l.Measurements[2].Name)
+	assert.True(t, math.IsNaN(l.Measurements[2].Values[0]))
+	assert.Equal(t, "period", l.Measurements[3].Name)
+	assert.Equal(t, []float64{0.001}, l.Measurements[3].Values)
+	assert.Equal(t, []string{"Direct Newton iteration failed to find .op point."}, l.Errors)
+}
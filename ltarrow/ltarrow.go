@@ -0,0 +1,191 @@
+// Package ltarrow converts LTSpice simulation data into Apache Arrow records and
+// writes them as Parquet or Arrow IPC files.
+//
+// Every variable of the simulation becomes a float64 column. Complex traces (AC analysis)
+// are split into two columns suffixed with "_re" and "_im", the x-axis is always written
+// as a single real column. A "step" column holds the step index of every row so stepped
+// simulations can be filtered and grouped. The simulation MetaData is stored as
+// schema (and Parquet file level) key/value metadata.
+package ltarrow
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"github.com/theadell/ltspice"
+)
+
+// StepColumn is the name of the column holding the step index of each row.
+const StepColumn = "step"
+
+// Metadata keys used to store the simulation MetaData in the schema.
+const (
+	KeyTitle     = "ltspice.title"
+	KeyDate      = "ltspice.date"
+	KeyPlotName  = "ltspice.plotname"
+	KeyFlags     = "ltspice.flags"
+	KeyCommand   = "ltspice.command"
+	KeyOffset    = "ltspice.offset"
+	KeyPoints    = "ltspice.points"
+	KeyVariables = "ltspice.variables"
+	KeySteps     = "ltspice.steps"
+)
+
+// KeyType is the field level metadata key holding the LTSpice variable type (time, voltage, device_current etc..)
+const KeyType = "ltspice.type"
+
+// NewRecord converts the simulation data into a single Arrow record.
+// The caller is responsible for releasing the returned record.
+func NewRecord(sim *ltspice.SimData, mem memory.Allocator) (arrow.Record, error) {
+	if mem == nil {
+		mem = memory.DefaultAllocator
+	}
+	schema := NewSchema(sim)
+	b := array.NewRecordBuilder(mem, schema)
+	defer b.Release()
+
+	vars := sim.GetVariables()
+	isComplex := sim.Meta.Flags&ltspice.Complex != 0
+
+	for step := 0; step < sim.GetSteps(); step++ {
+		xAxis := sim.GetXAxis(step)
+		n := len(xAxis)
+		stepCol := b.Field(0).(*array.Int32Builder)
+		for i := 0; i < n; i++ {
+			stepCol.Append(int32(step))
+		}
+		b.Field(1).(*array.Float64Builder).AppendValues(xAxis, nil)
+
+		col := 2
+		for _, v := range vars[1:] {
+			if isComplex {
+				t, err := ltspice.GetTrace[complex128](sim, v.Name)
+				if err != nil {
+					return nil, fmt.Errorf("ltarrow: %s: %w", v.Name, err)
+				}
+				signal := t.GetSignal(step)
+				if len(signal) != n {
+					return nil, fmt.Errorf("ltarrow: %s: expected %d points in step %d but found %d", v.Name, n, step, len(signal))
+				}
+				re := b.Field(col).(*array.Float64Builder)
+				im := b.Field(col + 1).(*array.Float64Builder)
+				for _, c := range signal {
+					re.Append(real(c))
+					im.Append(imag(c))
+				}
+				col += 2
+				continue
+			}
+			t, err := ltspice.GetTrace[float64](sim, v.Name)
+			if err != nil {
+				return nil, fmt.Errorf("ltarrow: %s: %w", v.Name, err)
+			}
+			signal := t.GetSignal(step)
+			if len(signal) != n {
+				return nil, fmt.Errorf("ltarrow: %s: expected %d points in step %d but found %d", v.Name, n, step, len(signal))
+			}
+			b.Field(col).(*array.Float64Builder).AppendValues(signal, nil)
+			col++
+		}
+	}
+	return b.NewRecord(), nil
+}
+
+// NewSchema returns the Arrow schema used to represent the simulation.
+// The first column is the step index followed by the x-axis and one column per trace.
+func NewSchema(sim *ltspice.SimData) *arrow.Schema {
+	vars := sim.GetVariables()
+	isComplex := sim.Meta.Flags&ltspice.Complex != 0
+
+	fields := make([]arrow.Field, 0, 2*len(vars)+1)
+	fields = append(fields, arrow.Field{Name: StepColumn, Type: arrow.PrimitiveTypes.Int32})
+	for i, v := range vars {
+		md := arrow.NewMetadata([]string{KeyType}, []string{v.Typ})
+		if isComplex && i > 0 {
+			fields = append(fields,
+				arrow.Field{Name: v.Name + "_re", Type: arrow.PrimitiveTypes.Float64, Metadata: md},
+				arrow.Field{Name: v.Name + "_im", Type: arrow.PrimitiveTypes.Float64, Metadata: md})
+			continue
+		}
+		fields = append(fields, arrow.Field{Name: v.Name, Type: arrow.PrimitiveTypes.Float64, Metadata: md})
+	}
+	md := schemaMetadata(sim)
+	return arrow.NewSchema(fields, &md)
+}
+
+func schemaMetadata(sim *ltspice.SimData) arrow.Metadata {
+	meta := sim.Meta
+	keys := []string{KeyTitle, KeyDate, KeyPlotName, KeyFlags, KeyCommand, KeyOffset, KeyPoints, KeyVariables, KeySteps}
+	values := []string{
+		meta.Title,
+		meta.Date.Format(time.RFC3339),
+		meta.SimType.String(),
+		meta.Flags.String(),
+		meta.Command,
+		strconv.FormatFloat(meta.Offset, 'g', -1, 64),
+		strconv.Itoa(meta.NoPoints),
+		strconv.Itoa(meta.NoVariables),
+		strconv.Itoa(sim.GetSteps()),
+	}
+	return arrow.NewMetadata(keys, values)
+}
+
+// WriteParquet writes the simulation to w as a Parquet file using snappy compression.
+//
+// Example usage:
+//
+//	f, err := os.Create("sim.parquet")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer f.Close()
+//	if err := ltarrow.WriteParquet(f, simData); err != nil {
+//	    log.Fatal(err)
+//	}
+func WriteParquet(w io.Writer, sim *ltspice.SimData) error {
+	rec, err := NewRecord(sim, memory.DefaultAllocator)
+	if err != nil {
+		return err
+	}
+	defer rec.Release()
+
+	props := parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy))
+	// the parquet writer closes its sink if it implements io.Closer, the caller owns w
+	fw, err := pqarrow.NewFileWriter(rec.Schema(), struct{ io.Writer }{w}, props, pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema()))
+	if err != nil {
+		return err
+	}
+	if err := fw.Write(rec); err != nil {
+		fw.Close()
+		return err
+	}
+	return fw.Close()
+}
+
+// WriteIPC writes the simulation to w in the Arrow IPC file format.
+func WriteIPC(w io.Writer, sim *ltspice.SimData) error {
+	rec, err := NewRecord(sim, memory.DefaultAllocator)
+	if err != nil {
+		return err
+	}
+	defer rec.Release()
+
+	fw, err := ipc.NewFileWriter(w, ipc.WithSchema(rec.Schema()))
+	if err != nil {
+		return err
+	}
+	if err := fw.Write(rec); err != nil {
+		fw.Close()
+		return err
+	}
+	return fw.Close()
+}
@@ -0,0 +1,124 @@
+package ltarrow
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theadell/ltspice"
+)
+
+func TestNewSchema(t *testing.T) {
+	sim, err := ltspice.Parse("../testdata/simulations/ac/low-pass/low-pass-filter.raw")
+	require.NoError(t, err)
+
+	schema := NewSchema(sim)
+	names := make([]string, 0, schema.NumFields())
+	for _, f := range schema.Fields() {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{
+		"step", "frequency",
+		"V(n001)_re", "V(n001)_im",
+		"V(n002)_re", "V(n002)_im",
+		"I(C1)_re", "I(C1)_im",
+		"I(R1)_re", "I(R1)_im",
+		"I(V1)_re", "I(V1)_im",
+	}, names)
+
+	title, ok := schema.Metadata().GetValue(KeyTitle)
+	assert.True(t, ok)
+	assert.Equal(t, sim.Meta.Title, title)
+	plot, _ := schema.Metadata().GetValue(KeyPlotName)
+	assert.Equal(t, "AC Analysis", plot)
+}
+
+func TestWriteParquetStepped(t *testing.T) {
+	sim, err := ltspice.Parse("../testdata/simulations/trans/stepped2/TRAN-STEP.raw")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteParquet(&buf, sim))
+
+	rdr, err := file.NewParquetReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	defer rdr.Close()
+
+	kv := rdr.MetaData().KeyValueMetadata()
+	assert.Equal(t, sim.Meta.Title, *kv.FindValue(KeyTitle))
+	assert.Equal(t, "Transient Analysis", *kv.FindValue(KeyPlotName))
+
+	fr, err := pqarrow.NewFileReader(rdr, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	require.NoError(t, err)
+	tbl, err := fr.ReadTable(context.Background())
+	require.NoError(t, err)
+	defer tbl.Release()
+
+	assert.EqualValues(t, sim.Meta.NoPoints, tbl.NumRows())
+	assert.EqualValues(t, len(sim.GetVariables())+1, tbl.NumCols())
+
+	steps := tbl.Column(0).Data().Chunk(0).(*array.Int32)
+	last := int32(sim.GetSteps() - 1)
+	assert.Equal(t, int32(0), steps.Value(0))
+	assert.Equal(t, last, steps.Value(steps.Len()-1))
+
+	vout, err := ltspice.GetTrace[float64](sim, "V(out)")
+	require.NoError(t, err)
+	idx := tbl.Schema().FieldIndices("V(out)")
+	require.Len(t, idx, 1)
+	col := tbl.Column(idx[0]).Data().Chunk(0).(*array.Float64)
+	assert.Equal(t, vout.Data, col.Float64Values())
+}
+
+func TestWriteIPC(t *testing.T) {
+	sim, err := ltspice.Parse("../testdata/simulations/ac/low-pass/low-pass-filter.raw")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteIPC(&buf, sim))
+
+	rdr, err := ipc.NewFileReader(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	defer rdr.Close()
+
+	rec, err := rdr.Record(0)
+	require.NoError(t, err)
+	assert.EqualValues(t, sim.Meta.NoPoints, rec.NumRows())
+
+	trace, err := ltspice.GetTrace[complex128](sim, "V(n002)")
+	require.NoError(t, err)
+	idx := rec.Schema().FieldIndices("V(n002)_im")
+	require.Len(t, idx, 1)
+	im := rec.Column(idx[0]).(*array.Float64)
+	for i, c := range trace.Data {
+		assert.Equal(t, imag(c), im.Value(i))
+	}
+}
+
+// closeRecorder is a writer which records whether it was closed.
+type closeRecorder struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestWriteParquetLeavesWriterOpen(t *testing.T) {
+	sim, err := ltspice.Parse("../testdata/simulations/ac/low-pass/low-pass-filter.raw")
+	require.NoError(t, err)
+
+	var w closeRecorder
+	require.NoError(t, WriteParquet(&w, sim))
+	assert.False(t, w.closed)
+	assert.NotZero(t, w.Len())
+}
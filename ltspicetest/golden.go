@@ -0,0 +1,296 @@
+// Package ltspicetest provides helpers for regression testing LTSpice simulation results
+// against golden files.
+//
+// Golden files are created or refreshed by running the tests with the -ltspicetest.update flag:
+//
+//	go test ./... -run TestAmplifier -ltspicetest.update
+package ltspicetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/theadell/ltspice"
+)
+
+// update is namespaced so it does not clash with an -update flag of the test package.
+var update = flag.Bool("ltspicetest.update", false, "update the golden files instead of comparing against them")
+
+// Tolerances configures which traces and measurements are snapshot and how much they may deviate.
+type Tolerances struct {
+	// Default applies to all traces and measurements without an explicit tolerance.
+	Default ltspice.Tolerance
+	// Traces selects the traces to snapshot with their tolerance. If empty, all traces are snapshot
+	// using the Default tolerance.
+	Traces map[string]ltspice.Tolerance
+	// Measurements selects the .meas results to snapshot with their tolerance. If empty, all
+	// measurements of the log passed with WithLog are snapshot using the Default tolerance.
+	Measurements map[string]ltspice.Tolerance
+}
+
+// Option configures AssertMatchesGolden.
+type Option func(*options)
+
+type options struct {
+	log *ltspice.LogFile
+}
+
+// WithLog includes the .meas results of the simulation log in the snapshot.
+func WithLog(l *ltspice.LogFile) Option {
+	return func(o *options) {
+		o.log = l
+	}
+}
+
+// golden is the content of a golden file.
+type golden struct {
+	Simulation   json.RawMessage       `json:"simulation"`
+	Measurements map[string][]*float64 `json:"measurements,omitempty"`
+}
+
+// AssertMatchesGolden compares the selected traces and measurements of sim against the golden file.
+//
+// The traces of sim are linearly interpolated onto the x-axis of the golden simulation, see
+// ltspice.Compare. Deviations beyond the tolerances are reported with t.Errorf together with the
+// x positions of the worst-offending points. If the test binary is run with -ltspicetest.update, the
+// golden file is (re)written instead. AssertMatchesGolden returns whether the comparison passed.
+//
+// Example usage:
+//
+//	sim, err := ltspice.Parse("amp.raw")
+//	require.NoError(t, err)
+//	ltspicetest.AssertMatchesGolden(t, sim, "testdata/golden/amp.json", ltspicetest.Tolerances{
+//	    Default: ltspice.Tolerance{Abs: 1e-6, Rel: 1e-3},
+//	    Traces:  map[string]ltspice.Tolerance{"V(out)": {Abs: 1e-3}},
+//	})
+func AssertMatchesGolden(t testing.TB, sim *ltspice.SimData, goldenFile string, tol Tolerances, opts ...Option) bool {
+	t.Helper()
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	snapshot, err := selectTraces(sim, tol)
+	if err != nil {
+		t.Errorf("ltspicetest: %v", err)
+		return false
+	}
+	measurements, err := selectMeasurements(o.log, tol)
+	if err != nil {
+		t.Errorf("ltspicetest: %v", err)
+		return false
+	}
+
+	if *update {
+		if err := writeGolden(goldenFile, snapshot, measurements); err != nil {
+			t.Errorf("ltspicetest: updating golden file: %v", err)
+			return false
+		}
+		t.Logf("ltspicetest: updated golden file %s", goldenFile)
+		return true
+	}
+
+	want, wantMeasurements, err := readGolden(goldenFile)
+	if errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ltspicetest: golden file %s does not exist, run the test with -ltspicetest.update to create it", goldenFile)
+		return false
+	}
+	if err != nil {
+		t.Errorf("ltspicetest: reading golden file %s: %v", goldenFile, err)
+		return false
+	}
+
+	passed := compareTraces(t, want, snapshot, tol)
+	return compareMeasurements(t, wantMeasurements, measurements, tol) && passed
+}
+
+func selectTraces(sim *ltspice.SimData, tol Tolerances) (*ltspice.SimData, error) {
+	if len(tol.Traces) == 0 {
+		return sim, nil
+	}
+	names := make([]string, 0, len(tol.Traces))
+	for name := range tol.Traces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return sim.SelectTraces(names...)
+}
+
+func selectMeasurements(l *ltspice.LogFile, tol Tolerances) (map[string][]float64, error) {
+	if l == nil {
+		if len(tol.Measurements) > 0 {
+			return nil, errors.New("measurement tolerances given without a log, use WithLog")
+		}
+		return nil, nil
+	}
+	out := make(map[string][]float64)
+	if len(tol.Measurements) == 0 {
+		for _, m := range l.Measurements {
+			out[strings.ToLower(m.Name)] = m.Values
+		}
+		return out, nil
+	}
+	for name := range tol.Measurements {
+		m, ok := l.Measurement(name)
+		if !ok {
+			return nil, fmt.Errorf("measurement %s not found in log", name)
+		}
+		out[strings.ToLower(m.Name)] = m.Values
+	}
+	return out, nil
+}
+
+func writeGolden(file string, sim *ltspice.SimData, measurements map[string][]float64) error {
+	var buf bytes.Buffer
+	if err := ltspice.WriteJSON(&buf, sim); err != nil {
+		return err
+	}
+	g := golden{Simulation: buf.Bytes()}
+	if len(measurements) > 0 {
+		g.Measurements = make(map[string][]*float64, len(measurements))
+		for name, values := range measurements {
+			// JSON has no NaN, failed measurements are stored as null
+			g.Measurements[name] = make([]*float64, len(values))
+			for i := range values {
+				if !math.IsNaN(values[i]) {
+					g.Measurements[name][i] = &values[i]
+				}
+			}
+		}
+	}
+	b, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(b, '\n'), 0o644)
+}
+
+func readGolden(file string) (*ltspice.SimData, map[string][]float64, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	var g golden
+	if err := json.Unmarshal(b, &g); err != nil {
+		return nil, nil, err
+	}
+	sim, err := ltspice.ReadJSON(bytes.NewReader(g.Simulation))
+	if err != nil {
+		return nil, nil, err
+	}
+	measurements := make(map[string][]float64, len(g.Measurements))
+	for name, values := range g.Measurements {
+		measurements[name] = make([]float64, len(values))
+		for i, v := range values {
+			measurements[name][i] = math.NaN()
+			if v != nil {
+				measurements[name][i] = *v
+			}
+		}
+	}
+	return sim, measurements, nil
+}
+
+func compareTraces(t testing.TB, want, got *ltspice.SimData, tol Tolerances) bool {
+	t.Helper()
+	opts := ltspice.CompareOptions{Tolerance: tol.Default}
+	if len(tol.Traces) > 0 {
+		opts.Traces = make(map[string]*ltspice.Tolerance, len(tol.Traces))
+		for name, tr := range tol.Traces {
+			opts.Traces[name] = &tr
+		}
+	}
+	report, err := ltspice.Compare(want, got, opts)
+	if err != nil {
+		t.Errorf("ltspicetest: %v", err)
+		return false
+	}
+
+	passed := true
+	if len(report.Removed) > 0 {
+		t.Errorf("ltspicetest: traces missing from the simulation: %s", strings.Join(report.Removed, ", "))
+		passed = false
+	}
+	if len(opts.Traces) == 0 && len(report.Added) > 0 {
+		t.Errorf("ltspicetest: traces missing from the golden file: %s", strings.Join(report.Added, ", "))
+		passed = false
+	}
+	if report.StepsA != report.StepsB {
+		t.Errorf("ltspicetest: golden file has %d steps, simulation has %d", report.StepsA, report.StepsB)
+		passed = false
+	}
+	for _, tr := range report.Traces {
+		for _, s := range tr.Steps {
+			if s.Failures == 0 {
+				continue
+			}
+			worst := make([]string, len(s.Worst))
+			for i, w := range s.Worst {
+				worst[i] = fmt.Sprintf("x=%g (abs %.4g, rel %.4g)", w.X, w.Abs, w.Rel)
+			}
+			t.Errorf("ltspicetest: %s step %d: %d of %d points exceed tolerance (abs %g, rel %g), worst at %s",
+				tr.Name, s.Step, s.Failures, s.Points, tr.Tolerance.Abs, tr.Tolerance.Rel, strings.Join(worst, ", "))
+			passed = false
+		}
+	}
+	return passed
+}
+
+func compareMeasurements(t testing.TB, want, got map[string][]float64, tol Tolerances) bool {
+	t.Helper()
+	names := make([]string, 0, len(want))
+	for name := range want {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	passed := true
+	for _, name := range names {
+		values, ok := got[name]
+		if !ok {
+			t.Errorf("ltspicetest: measurement %s missing from the log", name)
+			passed = false
+			continue
+		}
+		if len(values) != len(want[name]) {
+			t.Errorf("ltspicetest: measurement %s: golden file has %d steps, log has %d", name, len(want[name]), len(values))
+			passed = false
+			continue
+		}
+		mt := tol.Default
+		for n, tr := range tol.Measurements {
+			if strings.EqualFold(n, name) {
+				mt = tr
+			}
+		}
+		for step, w := range want[name] {
+			g := values[step]
+			if math.IsNaN(w) && math.IsNaN(g) {
+				continue
+			}
+			if diff := math.Abs(w - g); !(diff <= mt.Abs+mt.Rel*math.Abs(w)) {
+				t.Errorf("ltspicetest: measurement %s step %d: got %g, want %g (abs %g, rel %g)", name, step, g, w, mt.Abs, mt.Rel)
+				passed = false
+			}
+		}
+	}
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			t.Errorf("ltspicetest: measurement %s missing from the golden file", name)
+			passed = false
+		}
+	}
+	return passed
+}
@@ -0,0 +1,130 @@
+package ltspicetest
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/theadell/ltspice"
+)
+
+const steppedRaw = "../testdata/simulations/trans/stepped2/TRAN-STEP.raw"
+
+// recorder captures the errors reported by AssertMatchesGolden.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func setUpdate(t *testing.T, v bool) {
+	old := *update
+	*update = v
+	t.Cleanup(func() { *update = old })
+}
+
+func TestAssertMatchesGolden(t *testing.T) {
+	sim, err := ltspice.Parse(steppedRaw)
+	require.NoError(t, err)
+	file := filepath.Join(t.TempDir(), "golden", "tran.json")
+	tol := Tolerances{
+		Default: ltspice.Tolerance{Abs: 1e-9},
+		Traces:  map[string]ltspice.Tolerance{"V(out)": {Abs: 1e-3}, "I(R1)": {}},
+	}
+
+	setUpdate(t, true)
+	require.True(t, AssertMatchesGolden(t, sim, file, tol))
+
+	setUpdate(t, false)
+	r := &recorder{TB: t}
+	assert.True(t, AssertMatchesGolden(r, sim, file, tol))
+	assert.Empty(t, r.errors)
+
+	// the golden file only holds the selected traces
+	b, err := os.ReadFile(file)
+	require.NoError(t, err)
+	var g struct {
+		Simulation struct {
+			Steps []struct {
+				Traces map[string][]float64 `json:"traces"`
+			} `json:"steps"`
+		} `json:"simulation"`
+	}
+	require.NoError(t, json.Unmarshal(b, &g))
+	require.Len(t, g.Simulation.Steps, 4)
+	assert.Len(t, g.Simulation.Steps[0].Traces, 2)
+
+	// shift a single point of V(out) beyond its tolerance
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(b, &doc))
+	steps := doc["simulation"].(map[string]any)["steps"].([]any)
+	vout := steps[2].(map[string]any)["traces"].(map[string]any)["V(out)"].([]any)
+	vout[7] = vout[7].(float64) + 0.1
+	x := steps[2].(map[string]any)["x"].([]any)[7].(float64)
+	b, err = json.Marshal(doc)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(file, b, 0o644))
+
+	r = &recorder{TB: t}
+	assert.False(t, AssertMatchesGolden(r, sim, file, tol))
+	require.Len(t, r.errors, 1)
+	assert.Contains(t, r.errors[0], "V(out) step 2: 1 of")
+	assert.Contains(t, r.errors[0], fmt.Sprintf("worst at x=%g", x))
+}
+
+func TestAssertMatchesGoldenMeasurements(t *testing.T) {
+	sim, err := ltspice.Parse("../testdata/simulations/noise/noise.raw")
+	require.NoError(t, err)
+	l, err := ltspice.ParseLog("../testdata/simulations/noise/noise.log")
+	require.NoError(t, err)
+	file := filepath.Join(t.TempDir(), "noise.json")
+	tol := Tolerances{
+		Default:      ltspice.Tolerance{Rel: 1e-6},
+		Measurements: map[string]ltspice.Tolerance{"total_output_refered_rms_noise": {Rel: 0.01}},
+	}
+
+	setUpdate(t, true)
+	require.True(t, AssertMatchesGolden(t, sim, file, tol, WithLog(l)))
+
+	setUpdate(t, false)
+	m, ok := l.Measurement("total_output_refered_rms_noise")
+	require.True(t, ok)
+	m.Values[0] *= 1.005
+	r := &recorder{TB: t}
+	assert.True(t, AssertMatchesGolden(r, sim, file, tol, WithLog(l)), r.errors)
+
+	m.Values[0] *= 1.1
+	r = &recorder{TB: t}
+	assert.False(t, AssertMatchesGolden(r, sim, file, tol, WithLog(l)))
+	require.Len(t, r.errors, 1)
+	assert.Contains(t, r.errors[0], "measurement total_output_refered_rms_noise step 0")
+
+	r = &recorder{TB: t}
+	assert.False(t, AssertMatchesGolden(r, sim, file, tol))
+	assert.Contains(t, r.errors[0], "WithLog")
+}
+
+func TestAssertMatchesGoldenMissing(t *testing.T) {
+	sim, err := ltspice.Parse(steppedRaw)
+	require.NoError(t, err)
+
+	setUpdate(t, false)
+	r := &recorder{TB: t}
+	assert.False(t, AssertMatchesGolden(r, sim, filepath.Join(t.TempDir(), "missing.json"), Tolerances{}))
+	require.Len(t, r.errors, 1)
+	assert.Contains(t, r.errors[0], "-ltspicetest.update")
+}
+
+func TestUpdateFlagIsNamespaced(t *testing.T) {
+	// a test package importing ltspicetest may define its own -update flag
+	assert.Nil(t, flag.Lookup("update"))
+	assert.NotNil(t, flag.Lookup("ltspicetest.update"))
+}
@@ -0,0 +1,254 @@
+package ltspice
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// MATLAB Level 5 data types
+const (
+	miINT8   = 1
+	miUINT16 = 4
+	miINT32  = 5
+	miUINT32 = 6
+	miDOUBLE = 9
+	miMATRIX = 14
+)
+
+// MATLAB Level 5 array classes
+const (
+	mxCELL   = 1
+	mxSTRUCT = 2
+	mxCHAR   = 4
+	mxDOUBLE = 6
+)
+
+const (
+	matComplexFlag   = 0x08
+	matMaxNameLength = 63
+	matHeaderText    = 116
+	// MATMetaDataName is the name of the struct holding the simulation MetaData in a MAT file.
+	MATMetaDataName = "MetaData"
+)
+
+// WriteMAT writes the simulation as a MATLAB Level 5 MAT file.
+//
+// Each variable is written as a MATLAB variable with a sanitized name (e.g. V(out) becomes V_out,
+// Ix(u1:1) becomes Ix_u1_1), see MATName. Complex traces are written as complex arrays.
+// Stepped traces are written as a (points x steps) matrix when all steps have the same
+// number of points and as a (1 x steps) cell array of column vectors otherwise.
+//
+// The MetaData is written as a struct named MATMetaDataName, its Variables and Names fields
+// map the original variable names to the names used in the file.
+func WriteMAT(w io.Writer, sim *SimData) error {
+	header := fmt.Sprintf("MATLAB 5.0 MAT-file, Platform: Go, Created on: %s", time.Now().Format(time.ANSIC))
+	hdr := make([]byte, 128)
+	copy(hdr, header)
+	for i := len(header); i < matHeaderText; i++ {
+		hdr[i] = ' '
+	}
+	binary.LittleEndian.PutUint16(hdr[124:], 0x0100)
+	copy(hdr[126:], "IM")
+	if _, err := w.Write(hdr); err != nil {
+		return err
+	}
+
+	names := MATNames(sim.Meta.Variables)
+	for i, v := range sim.Meta.Variables {
+		var el []byte
+		if i > 0 && sim.Meta.Flags.hasFlag(Complex) {
+			el = matSteppedArray(names[i], sim.steps, sim.complexData[v.Name])
+		} else if i == 0 {
+			el = matSteppedArray(names[i], sim.steps, sim.flatXAxis())
+		} else {
+			el = matSteppedArray(names[i], sim.steps, sim.data[v.Name])
+		}
+		if _, err := w.Write(el); err != nil {
+			return fmt.Errorf("mat: failed to write %s: %w", v.Name, err)
+		}
+	}
+
+	_, err := w.Write(matMetaData(sim, names))
+	return err
+}
+
+// MATName converts an LTSpice variable name into a valid MATLAB variable name.
+// Characters which are not allowed in MATLAB identifiers are replaced by underscores,
+// e.g. V(out) becomes V_out and Ix(u1:1) becomes Ix_u1_1.
+func MATName(name string) string {
+	var sb strings.Builder
+	underscore := false
+	for _, r := range name {
+		if r < 128 && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+			underscore = false
+			continue
+		}
+		if !underscore {
+			sb.WriteByte('_')
+			underscore = true
+		}
+	}
+	s := strings.Trim(sb.String(), "_")
+	if s == "" || !(s[0] >= 'a' && s[0] <= 'z' || s[0] >= 'A' && s[0] <= 'Z') {
+		s = "x" + s
+	}
+	if len(s) > matMaxNameLength {
+		s = s[:matMaxNameLength]
+	}
+	return s
+}
+
+// MATNames returns the MATLAB variable names WriteMAT uses for the given variables.
+// Names which collide after sanitizing get a numeric suffix (V(+v) -> V_v, V(-v) -> V_v_2).
+func MATNames(vars []Variable) []string {
+	seen := map[string]bool{MATMetaDataName: true}
+	names := make([]string, len(vars))
+	for i, v := range vars {
+		base := MATName(v.Name)
+		name := base
+		for n := 2; seen[name]; n++ {
+			suffix := "_" + strconv.Itoa(n)
+			if len(base)+len(suffix) > matMaxNameLength {
+				base = base[:matMaxNameLength-len(suffix)]
+			}
+			name = base + suffix
+		}
+		seen[name] = true
+		names[i] = name
+	}
+	return names
+}
+
+func matSteppedArray[T float64 | complex128](name string, s *steps, data []T) []byte {
+	if s.count <= 1 {
+		return matNumeric(name, []int32{int32(len(data)), 1}, data)
+	}
+	bounds := make([]int, s.count+1)
+	copy(bounds, s.offsets)
+	bounds[s.count] = len(data)
+
+	n := bounds[1] - bounds[0]
+	uniform := true
+	for i := 1; i < s.count; i++ {
+		if bounds[i+1]-bounds[i] != n {
+			uniform = false
+			break
+		}
+	}
+	if uniform {
+		// MATLAB arrays are column major, so the flat data already is a points x steps matrix
+		return matNumeric(name, []int32{int32(n), int32(s.count)}, data)
+	}
+
+	cells := make([][]byte, s.count)
+	for i := range cells {
+		step := data[bounds[i]:bounds[i+1]]
+		cells[i] = matNumeric("", []int32{int32(len(step)), 1}, step)
+	}
+	return matMatrix(name, mxCELL, 0, []int32{1, int32(s.count)}, bytes.Join(cells, nil))
+}
+
+func matNumeric[T float64 | complex128](name string, dims []int32, data []T) []byte {
+	switch d := any(data).(type) {
+	case []float64:
+		return matMatrix(name, mxDOUBLE, 0, dims, matElement(miDOUBLE, d))
+	case []complex128:
+		re := make([]float64, len(d))
+		im := make([]float64, len(d))
+		for i, c := range d {
+			re[i], im[i] = real(c), imag(c)
+		}
+		return matMatrix(name, mxDOUBLE, matComplexFlag, dims, append(matElement(miDOUBLE, re), matElement(miDOUBLE, im)...))
+	}
+	return nil
+}
+
+func matString(name, s string) []byte {
+	chars := utf16.Encode([]rune(s))
+	return matMatrix(name, mxCHAR, 0, []int32{1, int32(len(chars))}, matElement(miUINT16, chars))
+}
+
+func matStringCell(name string, values []string) []byte {
+	cells := make([][]byte, len(values))
+	for i, v := range values {
+		cells[i] = matString("", v)
+	}
+	return matMatrix(name, mxCELL, 0, []int32{1, int32(len(values))}, bytes.Join(cells, nil))
+}
+
+func matStruct(name string, fields []string, values [][]byte) []byte {
+	fieldNameLength := 0
+	for _, f := range fields {
+		fieldNameLength = max(fieldNameLength, len(f)+1)
+	}
+	fieldNames := make([]byte, fieldNameLength*len(fields))
+	for i, f := range fields {
+		copy(fieldNames[i*fieldNameLength:], f)
+	}
+	body := matElement(miINT32, []int32{int32(fieldNameLength)})
+	body = append(body, matElement(miINT8, fieldNames)...)
+	for _, v := range values {
+		body = append(body, v...)
+	}
+	return matMatrix(name, mxSTRUCT, 0, []int32{1, 1}, body)
+}
+
+func matMetaData(sim *SimData, names []string) []byte {
+	meta := sim.Meta
+	original := make([]string, len(meta.Variables))
+	types := make([]string, len(meta.Variables))
+	for i, v := range meta.Variables {
+		original[i] = v.Name
+		types[i] = v.Typ
+	}
+	fields := []string{"Title", "Date", "PlotName", "Flags", "Command", "NoPoints", "NoVariables", "NoSteps", "Offset", "Variables", "Types", "Names"}
+	values := [][]byte{
+		matString("", meta.Title),
+		matString("", meta.Date.Format(time.RFC3339)),
+		matString("", meta.SimType.String()),
+		matString("", meta.Flags.String()),
+		matString("", meta.Command),
+		matNumeric("", []int32{1, 1}, []float64{float64(meta.NoPoints)}),
+		matNumeric("", []int32{1, 1}, []float64{float64(meta.NoVariables)}),
+		matNumeric("", []int32{1, 1}, []float64{float64(sim.steps.count)}),
+		matNumeric("", []int32{1, 1}, []float64{meta.Offset}),
+		matStringCell("", original),
+		matStringCell("", types),
+		matStringCell("", names),
+	}
+	return matStruct(MATMetaDataName, fields, values)
+}
+
+// matMatrix builds a miMATRIX data element. body contains the class specific sub elements
+// following the array name.
+func matMatrix(name string, class, flags byte, dims []int32, body []byte) []byte {
+	var sub []byte
+	sub = append(sub, matElement(miUINT32, []uint32{uint32(class) | uint32(flags)<<8, 0})...)
+	sub = append(sub, matElement(miINT32, dims)...)
+	sub = append(sub, matElement(miINT8, []byte(name))...)
+	sub = append(sub, body...)
+	return matTag(miMATRIX, sub)
+}
+
+// matElement encodes data as a little endian data element of the given type padded to 8 bytes.
+func matElement(typ uint32, data any) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, data)
+	return matTag(typ, buf.Bytes())
+}
+
+func matTag(typ uint32, data []byte) []byte {
+	padded := (len(data) + 7) &^ 7
+	el := make([]byte, 8+padded)
+	binary.LittleEndian.PutUint32(el[0:], typ)
+	binary.LittleEndian.PutUint32(el[4:], uint32(len(data)))
+	copy(el[8:], data)
+	return el
+}
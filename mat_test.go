@@ -0,0 +1,138 @@
+package ltspice
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMATName(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"V(out)", "V_out"},
+		{"I(R1)", "I_R1"},
+		{"Ix(u1:1)", "Ix_u1_1"},
+		{"V(+v)", "V_v"},
+		{"time", "time"},
+		{"V(q3.rc)", "V_q3_rc"},
+		{"1abc", "x1abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.want, MATName(tt.input))
+		})
+	}
+}
+
+func TestMATNamesCollisions(t *testing.T) {
+	vars := []Variable{{Name: "time"}, {Name: "V(+v)"}, {Name: "V(-v)"}, {Name: "MetaData"}}
+	assert.Equal(t, []string{"time", "V_v", "V_v_2", "MetaData_2"}, MATNames(vars))
+}
+
+// matVar is a decoded top level MAT file variable used for testing
+type matVar struct {
+	class   byte
+	complex bool
+	dims    []int32
+	name    string
+	real    []float64
+	imag    []float64
+}
+
+func readMATVars(t *testing.T, b []byte) map[string]matVar {
+	t.Helper()
+	require.Equal(t, "IM", string(b[126:128]))
+	vars := map[string]matVar{}
+	b = b[128:]
+	for len(b) > 0 {
+		typ := binary.LittleEndian.Uint32(b)
+		n := int(binary.LittleEndian.Uint32(b[4:]))
+		require.Equal(t, uint32(miMATRIX), typ)
+		v := decodeMATMatrix(t, b[8:8+n])
+		vars[v.name] = v
+		b = b[8+n:]
+	}
+	return vars
+}
+
+func decodeMATMatrix(t *testing.T, b []byte) matVar {
+	next := func() (uint32, []byte) {
+		typ := binary.LittleEndian.Uint32(b)
+		n := int(binary.LittleEndian.Uint32(b[4:]))
+		data := b[8 : 8+n]
+		b = b[8+(n+7)&^7:]
+		return typ, data
+	}
+	var v matVar
+	_, flags := next()
+	v.class = flags[0]
+	v.complex = flags[1]&matComplexFlag != 0
+	_, dims := next()
+	v.dims = make([]int32, len(dims)/4)
+	binary.Read(bytes.NewReader(dims), binary.LittleEndian, v.dims)
+	_, name := next()
+	v.name = string(name)
+	if v.class == mxDOUBLE {
+		_, re := next()
+		v.real = make([]float64, len(re)/8)
+		binary.Read(bytes.NewReader(re), binary.LittleEndian, v.real)
+		if v.complex {
+			_, im := next()
+			v.imag = make([]float64, len(im)/8)
+			binary.Read(bytes.NewReader(im), binary.LittleEndian, v.imag)
+		}
+	}
+	return v
+}
+
+func TestWriteMATComplex(t *testing.T) {
+	sim, err := Parse("testdata/simulations/ac/low-pass/low-pass-filter.raw")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMAT(&buf, sim))
+	vars := readMATVars(t, buf.Bytes())
+
+	assert.Contains(t, vars, MATMetaDataName)
+	assert.Equal(t, byte(mxSTRUCT), vars[MATMetaDataName].class)
+
+	freq := vars["frequency"]
+	assert.False(t, freq.complex)
+	assert.Equal(t, sim.GetXAxis(), freq.real)
+
+	v := vars["V_n002"]
+	require.True(t, v.complex)
+	assert.Equal(t, []int32{int32(sim.Meta.NoPoints), 1}, v.dims)
+	for i, c := range sim.complexData["V(n002)"] {
+		assert.Equal(t, real(c), v.real[i])
+		assert.Equal(t, imag(c), v.imag[i])
+	}
+}
+
+func TestWriteMATStepped(t *testing.T) {
+	sim, err := Parse("testdata/simulations/op/iter/iter.raw")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMAT(&buf, sim))
+	vars := readMATVars(t, buf.Bytes())
+
+	// every step of an operating point simulation holds a single point
+	v := vars["V_n001"]
+	assert.Equal(t, byte(mxDOUBLE), v.class)
+	assert.Equal(t, []int32{1, int32(sim.GetSteps())}, v.dims)
+	assert.Equal(t, sim.data["V(n001)"], v.real)
+
+	sim, err = Parse("testdata/simulations/stepped/rc/rc.raw")
+	require.NoError(t, err)
+	buf.Reset()
+	require.NoError(t, WriteMAT(&buf, sim))
+	vars = readMATVars(t, buf.Bytes())
+	assert.Equal(t, byte(mxCELL), vars["V_n002"].class)
+	assert.Equal(t, []int32{1, int32(sim.GetSteps())}, vars["V_n002"].dims)
+}
@@ -0,0 +1,241 @@
+package ltspice
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// boltzmann is the Boltzmann constant in J/K.
+const boltzmann = 1.380649e-23
+
+// noiseFigureTemperature is the reference temperature of the noise figure in K (IEEE standard).
+const noiseFigureTemperature = 290
+
+// NoiseContributor is the noise contribution of a device to the output noise of a noise analysis.
+type NoiseContributor struct {
+	// Name is the name of the device, e.g. "q3" or "r5", or of the noise mechanism for the sources of a device,
+	// e.g. "rb" for "V(q3.rb)".
+	Name string
+	// RMS is the integrated contribution in V or A.
+	RMS float64
+	// Fraction is the fraction of the total output noise power.
+	Fraction float64
+	// Sources holds the contributions of the noise mechanisms of the device, e.g. the base resistance
+	// and shot noise of a transistor, ranked by RMS.
+	Sources []NoiseContributor
+}
+
+// IntegratedNoise integrates the spectral density of a trace of a noise analysis, e.g. "V(onoise)", from fmin to
+// fmax and returns the RMS noise, like ".meas noise ... INTEG V(onoise)". Zero values for fmin and fmax select
+// the whole sweep. For stepped simulations a step index selects the step, the first step is used if none is given.
+func (sim *SimData) IntegratedNoise(name string, fmin, fmax float64, step ...int) (float64, error) {
+	if sim.GetType() != NoiseSpectralDensity {
+		return 0, fmt.Errorf("%w: integrated noise requires a noise analysis, got %s", ErrInvalidSimulationType, sim.GetType())
+	}
+	trace, err := GetTrace[float64](sim, name)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", err, name)
+	}
+	f := sim.GetXAxis(step...)
+	p, err := integratePower(f, trace.GetSignal(step...), fmin, fmax)
+	if err != nil {
+		return 0, err
+	}
+	return math.Sqrt(p), nil
+}
+
+// NoiseContributors ranks the devices of a noise analysis by their integrated contribution to the output noise
+// from fmin to fmax, largest first. Zero values for fmin and fmax select the whole sweep.
+//
+// The contributions of the noise mechanisms of a device, e.g. "V(q3.rc)", "V(q3.rb)" and "V(q3.sib)", are
+// grouped under the device "q3". If LTSpice wrote the total of the device, e.g. "V(q3)", it is used as the
+// contribution of the device, otherwise the sources are added as uncorrelated noise.
+//
+// Example usage:
+//
+//	contributors, err := simData.NoiseContributors(1, 10e3)
+//	for _, c := range contributors[:3] {
+//	    fmt.Printf("%s: %.3g V (%.1f%%)\n", c.Name, c.RMS, 100*c.Fraction)
+//	}
+func (sim *SimData) NoiseContributors(fmin, fmax float64, step ...int) ([]NoiseContributor, error) {
+	output, err := sim.noiseTrace("onoise")
+	if err != nil {
+		return nil, err
+	}
+	total, err := sim.IntegratedNoise(output, fmin, fmax, step...)
+	if err != nil {
+		return nil, err
+	}
+	power := total * total
+
+	var devices []*NoiseContributor
+	byName := map[string]*NoiseContributor{}
+	device := func(name string) *NoiseContributor {
+		if d, ok := byName[name]; ok {
+			return d
+		}
+		d := &NoiseContributor{Name: name, RMS: math.NaN()}
+		byName[name] = d
+		devices = append(devices, d)
+		return d
+	}
+	for _, v := range sim.Meta.Variables[1:] {
+		name := noiseSourceName(v.Name)
+		if v.Typ == "gain" || name == "onoise" || name == "inoise" || strings.Contains(v.Name, "noise_") {
+			continue
+		}
+		rms, err := sim.IntegratedNoise(v.Name, fmin, fmax, step...)
+		if err != nil {
+			return nil, err
+		}
+		c := NoiseContributor{Name: name, RMS: rms}
+		if power > 0 {
+			c.Fraction = rms * rms / power
+		}
+		if dev, mechanism, ok := cutLast(name, "."); ok {
+			d := device(dev)
+			c.Name = mechanism
+			d.Sources = append(d.Sources, c)
+			continue
+		}
+		d := device(name)
+		d.RMS, d.Fraction = c.RMS, c.Fraction
+	}
+
+	contributors := make([]NoiseContributor, 0, len(devices))
+	for _, d := range devices {
+		if math.IsNaN(d.RMS) {
+			// no total was written for the device
+			var p float64
+			for _, s := range d.Sources {
+				p += s.RMS * s.RMS
+			}
+			d.RMS = math.Sqrt(p)
+			if power > 0 {
+				d.Fraction = p / power
+			}
+		}
+		sortContributors(d.Sources)
+		contributors = append(contributors, *d)
+	}
+	sortContributors(contributors)
+	return contributors, nil
+}
+
+// NoiseFigure returns the noise figure in dB of a noise analysis whose input is driven by a source with the
+// resistance sourceResistance, integrated from fmin to fmax. If fmin equals fmax, the spot noise figure at
+// this frequency is returned. Zero values for fmin and fmax select the whole sweep.
+//
+// The noise figure relates the total output noise to the output noise caused by the thermal noise of the
+// source resistance at 290 K. The noise of the source resistance must be part of the simulated circuit, as
+// it is when the source resistance is a resistor in the netlist.
+func (sim *SimData) NoiseFigure(sourceResistance, fmin, fmax float64, step ...int) (float64, error) {
+	if sourceResistance <= 0 {
+		return 0, errors.New("noise figure: source resistance must be positive")
+	}
+	output, err := sim.noiseTrace("onoise")
+	if err != nil {
+		return 0, err
+	}
+	gain, err := sim.noiseTrace("gain")
+	if err != nil {
+		return 0, err
+	}
+	onoise, err := GetTrace[float64](sim, output)
+	if err != nil {
+		return 0, err
+	}
+	g, err := GetTrace[float64](sim, gain)
+	if err != nil {
+		return 0, err
+	}
+	f := sim.GetXAxis(step...)
+	density := 4 * boltzmann * noiseFigureTemperature * sourceResistance
+
+	if fmin == fmax && fmin != 0 {
+		n := interpLinear(f, onoise.GetSignal(step...), fmin)
+		a := interpLinear(f, g.GetSignal(step...), fmin)
+		return 10 * math.Log10(n*n/(a*a*density)), nil
+	}
+
+	total, err := integratePower(f, onoise.GetSignal(step...), fmin, fmax)
+	if err != nil {
+		return 0, err
+	}
+	source, err := integratePower(f, g.GetSignal(step...), fmin, fmax)
+	if err != nil {
+		return 0, err
+	}
+	return 10 * math.Log10(total/(source*density)), nil
+}
+
+// noiseTrace returns the name of the variable of a noise analysis with the given base name, e.g. "V(onoise)" or
+// "I(inoise)" for "onoise", "gain" for "gain" and "onoise_spectrum" as written by ngspice.
+func (sim *SimData) noiseTrace(base string) (string, error) {
+	if sim.GetType() != NoiseSpectralDensity {
+		return "", fmt.Errorf("%w: requires a noise analysis, got %s", ErrInvalidSimulationType, sim.GetType())
+	}
+	for _, v := range sim.Meta.Variables[1:] {
+		if strings.EqualFold(noiseSourceName(v.Name), base) || strings.EqualFold(v.Name, base+"_spectrum") {
+			return v.Name, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrTraceDoesNotExist, base)
+}
+
+// noiseSourceName strips the V() or I() around the name of a noise trace.
+func noiseSourceName(name string) string {
+	if len(name) > 3 && (name[0] == 'V' || name[0] == 'I') && name[1] == '(' && strings.HasSuffix(name, ")") {
+		return name[2 : len(name)-1]
+	}
+	return name
+}
+
+func cutLast(s, sep string) (string, string, bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
+
+func sortContributors(c []NoiseContributor) {
+	sort.SliceStable(c, func(i, j int) bool {
+		return c[i].RMS > c[j].RMS
+	})
+}
+
+// integratePower integrates the square of the spectral density d over the frequencies f from fmin to fmax using
+// the trapezoidal rule. The band edges are interpolated.
+func integratePower(f, d []float64, fmin, fmax float64) (float64, error) {
+	if len(f) < 2 {
+		return 0, errors.New("noise: at least two frequencies are required")
+	}
+	lo, hi := xRange(f)
+	if fmin == 0 && fmax == 0 {
+		fmin, fmax = lo, hi
+	}
+	if fmax <= fmin || fmin < lo || fmax > hi {
+		return 0, fmt.Errorf("noise: band [%g, %g] is not within the sweep [%g, %g]", fmin, fmax, lo, hi)
+	}
+
+	points := []float64{fmin}
+	for _, v := range f {
+		if v > fmin && v < fmax {
+			points = append(points, v)
+		}
+	}
+	points = append(points, fmax)
+
+	var p float64
+	prev := interpLinear(f, d, fmin)
+	for i := 1; i < len(points); i++ {
+		cur := interpLinear(f, d, points[i])
+		p += (prev*prev + cur*cur) / 2 * (points[i] - points[i-1])
+		prev = cur
+	}
+	return p, nil
+}
@@ -0,0 +1,138 @@
+package ltspice
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegratedNoise(t *testing.T) {
+	sim, err := Parse("testdata/simulations/noise/noise.raw")
+	require.NoError(t, err)
+	l, err := ParseLog("testdata/simulations/noise/noise.log")
+	require.NoError(t, err)
+
+	// matches .meas noise ... INTEG v(onoise) FROM 1 TO 10000
+	out, ok := l.Measurement("total_output_refered_rms_noise")
+	require.True(t, ok)
+	rms, err := sim.IntegratedNoise("V(onoise)", 1, 10e3)
+	require.NoError(t, err)
+	assert.InEpsilon(t, out.Values[0], rms, 1e-4)
+
+	in, ok := l.Measurement("total_input_refered_rms_noise")
+	require.True(t, ok)
+	rms, err = sim.IntegratedNoise("V(inoise)", 1, 10e3)
+	require.NoError(t, err)
+	assert.InEpsilon(t, in.Values[0], rms, 1e-4)
+
+	// the band edges are interpolated
+	part1, err := sim.IntegratedNoise("V(onoise)", 1, 123.4)
+	require.NoError(t, err)
+	part2, err := sim.IntegratedNoise("V(onoise)", 123.4, 10e3)
+	require.NoError(t, err)
+	assert.InEpsilon(t, out.Values[0]*out.Values[0], part1*part1+part2*part2, 1e-4)
+
+	whole, err := sim.IntegratedNoise("V(onoise)", 0, 0)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, whole, part1)
+
+	_, err = sim.IntegratedNoise("V(onoise)", 0.1, 10)
+	assert.Error(t, err)
+	_, err = sim.IntegratedNoise("V(missing)", 1, 10)
+	assert.ErrorIs(t, err, ErrTraceDoesNotExist)
+}
+
+func TestNoiseContributors(t *testing.T) {
+	sim, err := Parse("testdata/simulations/noise/noise.raw")
+	require.NoError(t, err)
+	contributors, err := sim.NoiseContributors(1, 10e3)
+	require.NoError(t, err)
+	// 8 transistors and 14 resistors
+	require.Len(t, contributors, 22)
+
+	var fraction float64
+	for i, c := range contributors {
+		fraction += c.Fraction
+		if i > 0 {
+			assert.LessOrEqual(t, c.RMS, contributors[i-1].RMS)
+		}
+		if c.Name[0] == 'r' {
+			assert.Empty(t, c.Sources, c.Name)
+			continue
+		}
+		require.Len(t, c.Sources, 6, c.Name)
+		// the sources of a device add up to its total
+		var p float64
+		for _, s := range c.Sources {
+			p += s.RMS * s.RMS
+		}
+		assert.InEpsilon(t, c.RMS*c.RMS, p, 1e-5, c.Name)
+		assert.GreaterOrEqual(t, c.Sources[0].RMS, c.Sources[5].RMS)
+	}
+	// the contributions are uncorrelated, so their powers add up to the output noise
+	assert.InDelta(t, 1, fraction, 1e-4)
+
+	var q3 NoiseContributor
+	for _, c := range contributors {
+		if c.Name == "q3" {
+			q3 = c
+		}
+	}
+	require.Equal(t, "q3", q3.Name)
+	names := map[string]bool{}
+	for _, s := range q3.Sources {
+		names[s.Name] = true
+	}
+	assert.Equal(t, map[string]bool{"rc": true, "rb": true, "re": true, "sic": true, "sib": true, "fib": true}, names)
+}
+
+func TestNoiseContributorsWithoutTotals(t *testing.T) {
+	sim, err := Parse("testdata/simulations/noise/noise.raw")
+	require.NoError(t, err)
+	var names []string
+	for _, v := range sim.GetVariables()[1:] {
+		if v.Name != "V(q3)" {
+			names = append(names, v.Name)
+		}
+	}
+	sel, err := sim.SelectTraces(names...)
+	require.NoError(t, err)
+
+	want, err := sim.NoiseContributors(0, 0)
+	require.NoError(t, err)
+	got, err := sel.NoiseContributors(0, 0)
+	require.NoError(t, err)
+	require.Len(t, got, len(want))
+	for i := range want {
+		assert.Equal(t, want[i].Name, got[i].Name)
+		assert.InEpsilon(t, want[i].RMS, got[i].RMS, 1e-5)
+	}
+}
+
+func TestNoiseFigure(t *testing.T) {
+	sim, err := Parse("testdata/simulations/noise/noise.raw")
+	require.NoError(t, err)
+
+	// spot noise figure from the input referred noise
+	f := sim.GetXAxis()[100]
+	inoise := sim.data["V(inoise)"][100]
+	nf, err := sim.NoiseFigure(50, f, f)
+	require.NoError(t, err)
+	assert.InDelta(t, 10*math.Log10(inoise*inoise/(4*boltzmann*290*50)), nf, 1e-3)
+
+	band, err := sim.NoiseFigure(50, 1, 10e3)
+	require.NoError(t, err)
+	assert.Greater(t, band, 0.0)
+
+	_, err = sim.NoiseFigure(0, 1, 10)
+	assert.Error(t, err)
+
+	tran, err := Parse("testdata/simulations/trans/LM741/LM741.raw")
+	require.NoError(t, err)
+	_, err = tran.NoiseFigure(50, 1, 10)
+	assert.ErrorIs(t, err, ErrInvalidSimulationType)
+	_, err = tran.NoiseContributors(1, 10)
+	assert.ErrorIs(t, err, ErrInvalidSimulationType)
+}
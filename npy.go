@@ -0,0 +1,107 @@
+package ltspice
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	npyMagic     = "\x93NUMPY"
+	npyAlignment = 64
+	// NPZStepOffsetsKey is the name of the array holding the start index of each step in an .npz archive.
+	NPZStepOffsetsKey = "step_offsets"
+)
+
+// WriteNPY writes data as a one dimensional NumPy .npy array (format version 1.0).
+// float64 data is written as '<f8', complex128 as '<c16' and int64 as '<i8'.
+func WriteNPY[T float64 | complex128 | int64](w io.Writer, data []T) error {
+	var descr string
+	switch any(data).(type) {
+	case []float64:
+		descr = "<f8"
+	case []complex128:
+		descr = "<c16"
+	case []int64:
+		descr = "<i8"
+	}
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': (%d,), }", descr, len(data))
+	// magic(6) + version(2) + header length(2) + header + '\n' must be a multiple of npyAlignment
+	pad := npyAlignment - (len(npyMagic)+4+len(header)+1)%npyAlignment
+	if pad == npyAlignment {
+		pad = 0
+	}
+	header += string(bytes.Repeat([]byte{' '}, pad)) + "\n"
+
+	buf := make([]byte, 0, len(npyMagic)+4+len(header))
+	buf = append(buf, npyMagic...)
+	buf = append(buf, 1, 0)
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(header)))
+	buf = append(buf, header...)
+	if _, err := w.Write(buf); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, data)
+}
+
+// WriteNPZ writes the simulation as a NumPy .npz archive.
+//
+// The archive contains one array per variable named after the variable. The x-axis is always
+// a float64 array, the remaining traces are float64 or complex128 depending on the simulation.
+// Stepped simulations are stored flat, the start index of each step is stored in the int64
+// array NPZStepOffsetsKey.
+//
+// Example usage (Python):
+//
+//	sim = numpy.load("sim.npz")
+//	t, vout, offsets = sim["time"], sim["V(out)"], sim["step_offsets"]
+func WriteNPZ(w io.Writer, sim *SimData) error {
+	zw := zip.NewWriter(w)
+
+	add := func(name string, write func(io.Writer) error) error {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: name + ".npy", Method: zip.Deflate})
+		if err != nil {
+			return err
+		}
+		return write(f)
+	}
+
+	for i, v := range sim.Meta.Variables {
+		var err error
+		switch {
+		case i == 0:
+			err = add(v.Name, func(w io.Writer) error { return WriteNPY(w, sim.flatXAxis()) })
+		case sim.Meta.Flags.hasFlag(Complex):
+			err = add(v.Name, func(w io.Writer) error { return WriteNPY(w, sim.complexData[v.Name]) })
+		default:
+			err = add(v.Name, func(w io.Writer) error { return WriteNPY(w, sim.data[v.Name]) })
+		}
+		if err != nil {
+			return fmt.Errorf("npz: failed to write %s: %w", v.Name, err)
+		}
+	}
+
+	offsets := make([]int64, len(sim.steps.offsets))
+	for i, o := range sim.steps.offsets {
+		offsets[i] = int64(o)
+	}
+	if err := add(NPZStepOffsetsKey, func(w io.Writer) error { return WriteNPY(w, offsets) }); err != nil {
+		return fmt.Errorf("npz: failed to write step offsets: %w", err)
+	}
+	return zw.Close()
+}
+
+// flatXAxis returns the x-axis data for all steps as a real valued slice.
+func (sim *SimData) flatXAxis() []float64 {
+	if !sim.Meta.Flags.hasFlag(Complex) {
+		return sim.data[sim.xAxisLabel]
+	}
+	c := sim.complexData[sim.xAxisLabel]
+	xAxis := make([]float64, len(c))
+	for i := range c {
+		xAxis[i] = real(c[i])
+	}
+	return xAxis
+}
@@ -0,0 +1,75 @@
+package ltspice
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteNPY(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteNPY(&buf, []complex128{complex(1, 2), complex(-3, 4)}))
+
+	b := buf.Bytes()
+	assert.Equal(t, npyMagic, string(b[:6]))
+	assert.Equal(t, []byte{1, 0}, b[6:8])
+	hlen := int(binary.LittleEndian.Uint16(b[8:10]))
+	assert.Zero(t, (10+hlen)%npyAlignment, "header is not aligned")
+
+	header := string(b[10 : 10+hlen])
+	assert.True(t, strings.HasSuffix(header, "\n"))
+	assert.Contains(t, header, "'descr': '<c16'")
+	assert.Contains(t, header, "'shape': (2,)")
+
+	got := make([]complex128, 2)
+	require.NoError(t, binary.Read(bytes.NewReader(b[10+hlen:]), binary.LittleEndian, got))
+	assert.Equal(t, []complex128{complex(1, 2), complex(-3, 4)}, got)
+}
+
+func TestWriteNPZ(t *testing.T) {
+	sim, err := Parse("testdata/simulations/trans/stepped2/TRAN-STEP.raw")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteNPZ(&buf, sim))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	arrays := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		arrays[strings.TrimSuffix(f.Name, ".npy")] = b
+	}
+	assert.Len(t, arrays, len(sim.GetVariables())+1)
+
+	vout := readNPYFloats(t, arrays["V(out)"])
+	assert.Equal(t, sim.data["V(out)"], vout)
+
+	offsets := arrays[NPZStepOffsetsKey]
+	hlen := int(binary.LittleEndian.Uint16(offsets[8:10]))
+	got := make([]int64, sim.GetSteps())
+	require.NoError(t, binary.Read(bytes.NewReader(offsets[10+hlen:]), binary.LittleEndian, got))
+	for i, o := range sim.steps.offsets {
+		assert.Equal(t, int64(o), got[i])
+	}
+}
+
+func readNPYFloats(t *testing.T, b []byte) []float64 {
+	t.Helper()
+	hlen := int(binary.LittleEndian.Uint16(b[8:10]))
+	assert.Contains(t, string(b[10:10+hlen]), "'descr': '<f8'")
+	data := make([]float64, (len(b)-10-hlen)/8)
+	require.NoError(t, binary.Read(bytes.NewReader(b[10+hlen:]), binary.LittleEndian, data))
+	return data
+}
@@ -0,0 +1,133 @@
+package ltspice
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ParseOption configures optional behaviour of Parse.
+type ParseOption func(*parseOptions)
+
+type parseOptions struct {
+	operatingPoint bool
+}
+
+// WithOperatingPoint makes Parse look for the companion operating point file LTSpice writes next to
+// the raw file of transient, AC and noise analyses, e.g. "LM741.op.raw" for "LM741.raw", and attach it
+// to the simulation. A missing companion file is not an error, OperatingPoint then returns ErrNoOperatingPoint.
+//
+// Example usage:
+//
+//	sim, err := ltspice.Parse("LM741.raw", ltspice.WithOperatingPoint())
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	bias, err := sim.OperatingPoint()
+func WithOperatingPoint() ParseOption {
+	return func(o *parseOptions) {
+		o.operatingPoint = true
+	}
+}
+
+// BiasPoint holds the DC operating point of a circuit.
+type BiasPoint struct {
+	// Voltages holds the node voltages keyed by trace name, e.g. "V(out)".
+	Voltages map[string]float64
+	// Currents holds the device and subcircuit currents keyed by trace name, e.g. "I(R1)" or "Ib(Q1)".
+	Currents map[string]float64
+	// Params holds the values of stepped parameters, if any.
+	Params map[string]float64
+}
+
+// Voltage returns the voltage of the given node, e.g. "out" or "V(out)".
+func (b *BiasPoint) Voltage(node string) (float64, bool) {
+	if v, ok := b.Voltages[node]; ok {
+		return v, true
+	}
+	v, ok := b.Voltages["V("+node+")"]
+	return v, ok
+}
+
+// Current returns the current of the given trace, e.g. "I(R1)".
+func (b *BiasPoint) Current(name string) (float64, bool) {
+	v, ok := b.Currents[name]
+	return v, ok
+}
+
+// OperatingPoint returns the bias point of the simulation. For stepped simulations a step index selects the
+// operating point of that step, the first step is used if none is given.
+//
+// The bias point is read from the simulation itself for operating point analyses and from the companion
+// file loaded with WithOperatingPoint or attached with SetOperatingPoint otherwise. If there is none,
+// ErrNoOperatingPoint is returned.
+func (sim *SimData) OperatingPoint(step ...int) (*BiasPoint, error) {
+	op := sim.op
+	if sim.GetType() == OperatingPoint {
+		op = sim
+	}
+	if op == nil {
+		return nil, ErrNoOperatingPoint
+	}
+
+	s := 0
+	if len(step) > 0 {
+		s = step[0]
+	}
+	if s < 0 || s >= op.steps.count {
+		return nil, fmt.Errorf("%w: step %d out of range, the operating point has %d steps", ErrNoOperatingPoint, s, op.steps.count)
+	}
+	idx := op.steps.offsets[s]
+
+	bias := &BiasPoint{
+		Voltages: make(map[string]float64),
+		Currents: make(map[string]float64),
+		Params:   make(map[string]float64),
+	}
+	for _, v := range op.Meta.Variables {
+		values := op.data[v.Name]
+		if idx >= len(values) {
+			continue
+		}
+		switch {
+		case v.Typ == "voltage":
+			bias.Voltages[v.Name] = values[idx]
+		case strings.HasSuffix(v.Typ, "current"):
+			bias.Currents[v.Name] = values[idx]
+		default:
+			bias.Params[v.Name] = values[idx]
+		}
+	}
+	return bias, nil
+}
+
+// SetOperatingPoint attaches the operating point analysis op to the simulation, see OperatingPoint.
+func (sim *SimData) SetOperatingPoint(op *SimData) error {
+	if op.GetType() != OperatingPoint {
+		return fmt.Errorf("%w: expected %s, got %s", ErrInvalidSimulationType, OperatingPoint, op.GetType())
+	}
+	sim.op = op
+	return nil
+}
+
+// operatingPointFile returns the name of the companion operating point file of a raw file.
+func operatingPointFile(fileName string) string {
+	return strings.TrimSuffix(fileName, filepath.Ext(fileName)) + ".op.raw"
+}
+
+// loadOperatingPoint attaches the companion operating point file of fileName to sim if it exists.
+func loadOperatingPoint(sim *SimData, fileName string) error {
+	if sim.GetType() == OperatingPoint {
+		return nil
+	}
+	op, err := Parse(operatingPointFile(fileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("operating point: %w", err)
+	}
+	return sim.SetOperatingPoint(op)
+}
@@ -0,0 +1,93 @@
+package ltspice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWithOperatingPoint(t *testing.T) {
+	sim, err := Parse("testdata/simulations/trans/LM741/LM741.raw", WithOperatingPoint())
+	require.NoError(t, err)
+	assert.Equal(t, TransientAnalysis, sim.GetType())
+
+	bias, err := sim.OperatingPoint()
+	require.NoError(t, err)
+	assert.Len(t, bias.Voltages, 25)
+	assert.Len(t, bias.Currents, 78)
+	assert.Empty(t, bias.Params)
+	assert.InDelta(t, 14.3289, bias.Voltages["V(n001)"], 1e-4)
+
+	v, ok := bias.Voltage("n001")
+	require.True(t, ok)
+	assert.Equal(t, bias.Voltages["V(n001)"], v)
+	i, ok := bias.Current("Ic(Q19)")
+	require.True(t, ok)
+	assert.InDelta(t, -2.6919e-6, i, 1e-10)
+	_, ok = bias.Current("I(missing)")
+	assert.False(t, ok)
+
+	// subcircuit currents are currents too
+	ac, err := Parse("testdata/simulations/ac/Loop-Gain/LoopGain.raw", WithOperatingPoint())
+	require.NoError(t, err)
+	bias, err = ac.OperatingPoint()
+	require.NoError(t, err)
+	assert.Len(t, bias.Currents, 24)
+
+	// the bias point survives selecting traces
+	sel, err := sim.SelectTraces("V(n001)")
+	require.NoError(t, err)
+	_, err = sel.OperatingPoint()
+	assert.NoError(t, err)
+}
+
+func TestOperatingPointMissing(t *testing.T) {
+	sim, err := Parse("testdata/simulations/trans/LM741/LM741.raw")
+	require.NoError(t, err)
+	_, err = sim.OperatingPoint()
+	assert.ErrorIs(t, err, ErrNoOperatingPoint)
+
+	// no companion file
+	sim, err = Parse("testdata/simulations/stepped/rc/rc.raw", WithOperatingPoint())
+	require.NoError(t, err)
+	_, err = sim.OperatingPoint()
+	assert.ErrorIs(t, err, ErrNoOperatingPoint)
+
+	assert.ErrorIs(t, sim.SetOperatingPoint(sim), ErrInvalidSimulationType)
+}
+
+func TestOperatingPointStepped(t *testing.T) {
+	sim, err := Parse("testdata/simulations/op/iter/iter.raw")
+	require.NoError(t, err)
+	require.Equal(t, 5, sim.GetSteps())
+
+	for step := 0; step < 5; step++ {
+		bias, err := sim.OperatingPoint(step)
+		require.NoError(t, err)
+		x := float64(10 * (step + 1))
+		assert.Equal(t, x, bias.Params["x"])
+		assert.Equal(t, x, bias.Voltages["V(n001)"])
+		assert.Equal(t, -x/10, bias.Currents["I(R1)"])
+	}
+	_, err = sim.OperatingPoint(5)
+	assert.ErrorIs(t, err, ErrNoOperatingPoint)
+
+	// a stepped bias point is selected along with the steps of the simulation
+	tran, err := Parse("testdata/simulations/trans/LM741/LM741.raw")
+	require.NoError(t, err)
+	require.NoError(t, tran.SetOperatingPoint(sim))
+	sel, err := tran.SelectSteps(0)
+	require.NoError(t, err)
+	bias, err := sel.OperatingPoint()
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, bias.Params["x"])
+
+	stepped, err := Parse("testdata/simulations/trans/stepped/tran-stepped.raw", WithOperatingPoint())
+	require.NoError(t, err)
+	bias, err = stepped.OperatingPoint()
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, bias.Params["x"])
+	assert.Len(t, bias.Voltages, 2)
+	assert.Len(t, bias.Currents, 3)
+}
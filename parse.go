@@ -5,11 +5,14 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"math"
 	"os"
+	"strconv"
 	"strings"
+	"unicode"
 	"unicode/utf16"
 )
 
@@ -21,13 +24,20 @@ const dateHeaderLayout = "Date: Mon Jan 2 15:04:05 2006"
 // RAW file.
 // If an error occurs during parsing, it returns a non-nil error.
 //
+// Options such as WithOperatingPoint enable optional behaviour.
+//
 // Example usage:
 //
 //	simData, err := ltspice.Parse("path/to/ltspice.raw")
 //	if err != nil {
 //	    log.Fatalf("Failed to parse LTSpice raw data: %v", err)
 //	}
-func Parse(fileName string) (*SimData, error) {
+func Parse(fileName string, opts ...ParseOption) (*SimData, error) {
+	var o parseOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	file, err := os.Open(fileName)
 	if err != nil {
 		return nil, err
@@ -35,45 +45,57 @@ func Parse(fileName string) (*SimData, error) {
 	defer file.Close()
 
 	reader := bufio.NewReader(file)
-	return ParseFromReader(reader)
+	sim, err := ParseFromReader(reader)
+	if err != nil {
+		return nil, err
+	}
+	if o.operatingPoint {
+		if err := loadOperatingPoint(sim, fileName); err != nil {
+			return nil, err
+		}
+	}
+	return sim, nil
 }
 
 // ParseFromReader parses LTSpice raw data file from the provided io.Reader.
 // It returns the parsed simulation data as a SimData object, or a non-nil error if an error occurs.
+//
+// Raw files written by ngspice and Qspice (.qraw) are supported as well: the header encoding (UTF-16 for LTSpice, ASCII
+// for ngspice) and binary or ASCII ("Values:") data sections are detected automatically. If the file
+// holds multiple plots, the first one is returned.
 func ParseFromReader(reader io.Reader) (*SimData, error) {
-	meta, err := parseHeaders(reader)
-	if err != nil {
-		return nil, err
-	}
-
-	toReadBytes := 0
-	for _, t := range meta.Variables {
-		toReadBytes += (t.size * meta.NoPoints)
-	}
+	return parsePlot(newHeaderReader(reader))
+}
 
-	binaryData, err := io.ReadAll(reader)
-	if err != nil {
+// parsePlot parses a single plot, leaving r positioned at the start of the next plot.
+func parsePlot(r *headerReader) (*SimData, error) {
+	meta, ascii, err := readHeaders(r)
+	if err != nil && !errors.Is(err, ErrInvalidSimulationType) {
 		return nil, err
 	}
-
-	r := bytes.NewReader(binaryData)
+	// the data of unsupported plots is still read so r ends up at the next plot
+	unsupported := err
+	if len(meta.Variables) == 0 {
+		return nil, fmt.Errorf("%w: no variables", ErrInvalidSimulationHeader)
+	}
 
 	sim := &SimData{
 		Meta:       meta,
 		xAxisLabel: meta.Variables[0].Name,
 	}
-	if !meta.Flags.hasFlag(Complex) {
-		data, err := parseBinaryData(r, meta)
-		if err != nil {
-			return nil, err
-		}
-		sim.data = data
-	} else {
-		data, err := parseBinaryComplex(r, meta)
-		if err != nil {
-			return nil, err
-		}
-		sim.complexData = data
+	switch {
+	case ascii:
+		err = parseASCIIData(r, sim)
+	case !meta.Flags.hasFlag(Complex):
+		sim.data, err = parseBinaryData(r.r, meta)
+	default:
+		sim.complexData, err = parseBinaryComplex(r.r, meta)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if unsupported != nil {
+		return nil, unsupported
 	}
 	steps := &steps{
 		count:   1,
@@ -84,16 +106,12 @@ func ParseFromReader(reader io.Reader) (*SimData, error) {
 		switch sim.Meta.SimType {
 		case OperatingPoint, TransferFunction:
 			steps.count = len(sim.data[sim.GetVariables()[0].Name])
-		default:
-			var xAxis []float64
-			if sim.Meta.Flags.hasFlag(Complex) {
-				for _, c := range sim.complexData[sim.xAxisLabel] {
-					xAxis = append(xAxis, real(c))
-				}
-			} else {
-				xAxis = sim.data[sim.xAxisLabel]
+			steps.offsets = make([]int, steps.count)
+			for i := range steps.offsets {
+				steps.offsets[i] = i
 			}
-			steps, err = detectSteps(xAxis)
+		default:
+			steps, err = detectSteps(sim.flatXAxis())
 			if err != nil {
 				return nil, err
 			}
@@ -105,24 +123,55 @@ func ParseFromReader(reader io.Reader) (*SimData, error) {
 }
 
 func parseHeaders(reader io.Reader) (*MetaData, error) {
+	meta, _, err := readHeaders(newHeaderReader(reader))
+	if err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// readHeaders reads the header of a plot and reports whether the data section is ASCII encoded.
+// If the plot type is not supported, the whole header is read and the metadata is returned together
+// with ErrInvalidSimulationType.
+func readHeaders(r *headerReader) (*MetaData, bool, error) {
 	var metadata = &MetaData{Flags: None}
+	var plotErr error
+	ascii := false
 	for {
-		line, err := readLineUTF16(reader)
+		line, err := r.readLine()
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
-		if strings.Contains(strings.ToLower(strings.TrimSpace(line)), headerBinary) || strings.Contains(strings.ToLower(strings.TrimSpace(line)), headerValues) {
+		lower := strings.ToLower(strings.TrimSpace(line))
+		if strings.Contains(lower, headerBinary) {
+			break
+		}
+		if strings.Contains(lower, headerValues) {
+			ascii = true
 			break
 		}
 		if line == "" {
 			continue
 		}
-		err = parseHeaderLine(reader, metadata, line)
+		err = parseHeaderLine(r, metadata, line)
+		if errors.Is(err, ErrInvalidSimulationType) {
+			plotErr = err
+			continue
+		}
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 	}
-	return metadata, nil
+	if !r.utf16 || strings.Contains(strings.ToUpper(metadata.Command), "QSPICE") {
+		// ngspice and Qspice store all variables as doubles
+		metadata.Flags.setFlag(Double)
+	}
+	if metadata.Flags.hasFlag(Double) {
+		for i := range metadata.Variables {
+			metadata.Variables[i].size = realXAxisTraceByteSize
+		}
+	}
+	return metadata, ascii, plotErr
 }
 
 func parseBinaryData(reader io.Reader, meta *MetaData) (map[string][]float64, error) {
@@ -143,6 +192,10 @@ func parseBinaryData(reader io.Reader, meta *MetaData) (map[string][]float64, er
 			} else {
 				val = toFloat(buff[:v.size])
 			}
+			if v.Typ == "time" {
+				// LTSpice uses the sign bit of the time axis as a flag, time itself is never negative
+				val = math.Abs(val)
+			}
 			data[v.Name][i] = val
 		}
 	}
@@ -170,6 +223,147 @@ func parseBinaryComplex(reader io.Reader, meta *MetaData) (map[string][]complex1
 	}
 	return data, nil
 }
+
+// headerReader reads the lines of a raw file, which are UTF-16LE encoded in LTSpice files
+// and ASCII encoded in ngspice files.
+type headerReader struct {
+	r     *bufio.Reader
+	utf16 bool
+}
+
+func newHeaderReader(reader io.Reader) *headerReader {
+	r, ok := reader.(*bufio.Reader)
+	if !ok {
+		r = bufio.NewReader(reader)
+	}
+	if b, _ := r.Peek(3); bytes.Equal(b, []byte{0xEF, 0xBB, 0xBF}) {
+		// UTF-8 byte order mark, e.g. written by Qspice
+		r.Discard(3)
+	}
+	b, _ := r.Peek(2)
+	return &headerReader{r: r, utf16: len(b) == 2 && b[1] == 0}
+}
+
+func (h *headerReader) readLine() (string, error) {
+	if h.utf16 {
+		return readLineUTF16(h.r)
+	}
+	return readLineASCII(h.r)
+}
+
+// readToken reads the next whitespace separated token, e.g. a value of an ASCII data section.
+func (h *headerReader) readToken() (string, error) {
+	var token []rune
+	for {
+		var c rune
+		if h.utf16 {
+			var buff [2]byte
+			if _, err := io.ReadFull(h.r, buff[:]); err != nil {
+				if len(token) > 0 && errors.Is(err, io.EOF) {
+					return string(token), nil
+				}
+				return "", ErrUnexpectedEndOfFile
+			}
+			c = rune(binary.LittleEndian.Uint16(buff[:]))
+		} else {
+			b, err := h.r.ReadByte()
+			if err != nil {
+				if len(token) > 0 && errors.Is(err, io.EOF) {
+					return string(token), nil
+				}
+				return "", ErrUnexpectedEndOfFile
+			}
+			c = rune(b)
+		}
+		if unicode.IsSpace(c) {
+			if len(token) > 0 {
+				return string(token), nil
+			}
+			continue
+		}
+		token = append(token, c)
+	}
+}
+
+// atEOF skips whitespace and reports whether the end of the input is reached.
+func (h *headerReader) atEOF() bool {
+	size := 1
+	if h.utf16 {
+		size = 2
+	}
+	for {
+		b, err := h.r.Peek(size)
+		if err != nil {
+			return true
+		}
+		if !unicode.IsSpace(rune(b[0])) || h.utf16 && b[1] != 0 {
+			return false
+		}
+		h.r.Discard(size)
+	}
+}
+
+func readLineASCII(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return "", ErrUnexpectedEndOfFile
+		}
+		return "", ErrParsingError
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// parseASCIIData parses a "Values:" data section. Every point starts with its index followed by
+// the values of all variables, complex values are written as "re,im".
+func parseASCIIData(r *headerReader, sim *SimData) error {
+	meta := sim.Meta
+	isComplex := meta.Flags.hasFlag(Complex)
+	if isComplex {
+		sim.complexData = make(map[string][]complex128, len(meta.Variables))
+	} else {
+		sim.data = make(map[string][]float64, len(meta.Variables))
+	}
+	for _, v := range meta.Variables {
+		if isComplex {
+			sim.complexData[v.Name] = make([]complex128, meta.NoPoints)
+		} else {
+			sim.data[v.Name] = make([]float64, meta.NoPoints)
+		}
+	}
+
+	for i := 0; i < meta.NoPoints; i++ {
+		if _, err := r.readToken(); err != nil {
+			return err
+		}
+		for _, v := range meta.Variables {
+			token, err := r.readToken()
+			if err != nil {
+				return err
+			}
+			if isComplex {
+				re, im, _ := strings.Cut(token, ",")
+				a, err1 := strconv.ParseFloat(re, 64)
+				b, err2 := strconv.ParseFloat(im, 64)
+				if err1 != nil || err2 != nil {
+					return fmt.Errorf("%w: invalid complex value %q of %s", ErrParsingError, token, v.Name)
+				}
+				sim.complexData[v.Name][i] = complex(a, b)
+				continue
+			}
+			val, err := strconv.ParseFloat(token, 64)
+			if err != nil {
+				return fmt.Errorf("%w: invalid value %q of %s", ErrParsingError, token, v.Name)
+			}
+			if v.Typ == "time" {
+				val = math.Abs(val)
+			}
+			sim.data[v.Name][i] = val
+		}
+	}
+	return nil
+}
+
 func readLineUTF16(r io.Reader) (string, error) {
 	lineBuff := make([]uint16, 0, maxLineSize)
 	buff := make([]byte, 2)
@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/csv"
+	"math"
 	"math/cmplx"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
+	"unicode/utf16"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -182,6 +185,7 @@ func TestParsingNoiseSpectralSimulation(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	assert.Equal(t, "out", s.Meta.Output)
 
 	resultSet, err := csvToMap(resultsPath)
 	if err != nil {
@@ -256,3 +260,132 @@ func csvToMap(filename string) (map[string][]float64, error) {
 
 	return columns, nil
 }
+
+func TestSteppedOperatingPoint(t *testing.T) {
+	s, err := Parse("testdata/simulations/op/iter/iter.raw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trace, err := GetTrace[float64](s, "V(n001)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 5, s.GetSteps())
+	for step := 0; step < s.GetSteps(); step++ {
+		assert.Equal(t, []float64{trace.Data[step]}, trace.GetSignal(step))
+	}
+}
+
+func TestTransientTimeAxisIsPositive(t *testing.T) {
+	s, err := Parse("testdata/simulations/trans/LM741/LM741.raw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for idx, v := range s.GetXAxis() {
+		if v < 0 {
+			t.Fatalf("negative time %g at index %d", v, idx)
+		}
+	}
+}
+
+func TestParseNgspice(t *testing.T) {
+	// rc.raw holds an AC analysis followed by a transient analysis, the first plot is returned
+	s, err := Parse("testdata/simulations/ngspice/rc.raw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ACAnalysis, s.GetType())
+	assert.True(t, s.Meta.Flags.hasFlag(Complex|Double))
+	assert.Equal(t, "* rc low pass", s.Meta.Title)
+	assert.Equal(t, 2024, s.Meta.Date.Year())
+	vout, err := GetTrace[complex128](s, "v(out)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// -3 dB at 1/(2*pi*RC) ~ 159 Hz
+	f := s.GetXAxis()
+	for i, v := range vout.GetSignal() {
+		want := 1 / complex(1, 2*math.Pi*f[i]*1e-3)
+		assert.InDelta(t, 0, cmplx.Abs(v-want), 1e-12)
+	}
+}
+
+func TestParseNgspiceASCII(t *testing.T) {
+	s, err := Parse("testdata/simulations/ngspice/rc-ascii.raw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, OperatingPoint, s.GetType())
+	assert.Equal(t, []float64{1}, s.data["v(out)"])
+
+	s, err = Parse("testdata/simulations/ngspice/rc-ac-ascii.raw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ACAnalysis, s.GetType())
+	assert.InDeltaSlice(t, []float64{10, math.Pow(10, 1.5)}, s.GetXAxis()[:2], 1e-12)
+	assert.InDelta(t, 1/math.Sqrt(1+math.Pow(2*math.Pi*10e-3, 2)), cmplx.Abs(s.complexData["v(out)"][0]), 1e-12)
+}
+
+func TestParseLTSpiceASCII(t *testing.T) {
+	raw := strings.Join([]string{
+		"Title: * ascii.asc",
+		"Date: Mon Mar 30 14:21:21 2020",
+		"Plotname: Transient Analysis",
+		"Flags: real forward",
+		"No. Variables: 2",
+		"No. Points: 3",
+		"Offset: 0",
+		"Command: Linear Technology Corporation LTspice XVII",
+		"Variables:",
+		"\t0\ttime\ttime",
+		"\t1\tV(out)\tvoltage",
+		"Values:",
+		"0\t0.000000000000000e+000",
+		"\t1.0",
+		"1\t-1.000000000000000e-003",
+		"\t2.5",
+		"2\t2.000000000000000e-003",
+		"\t3.0",
+		"",
+	}, "\n")
+	var b bytes.Buffer
+	for _, u := range utf16.Encode([]rune(raw)) {
+		binary.Write(&b, binary.LittleEndian, u)
+	}
+	s, err := ParseFromReader(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, s.Meta.Flags.hasFlag(Double))
+	assert.Equal(t, []float64{0, 1e-3, 2e-3}, s.GetXAxis())
+	assert.Equal(t, []float64{1, 2.5, 3}, s.data["V(out)"])
+}
+
+func TestParseQspice(t *testing.T) {
+	s, err := Parse("testdata/simulations/qspice/rc.qraw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, TransientAnalysis, s.GetType())
+	assert.Equal(t, "QSPICE64", s.Meta.Command)
+	assert.True(t, s.Meta.Flags.hasFlag(Stepped|Double))
+	assert.Equal(t, 2, s.GetSteps())
+	assert.Len(t, s.GetXAxis(1), 26)
+
+	vout, err := GetTrace[float64](s, "V(out)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// one time constant after the step: 1 - 1/e
+	assert.InDelta(t, 1-math.Exp(-1), vout.GetSignal(0)[5], 1e-12)
+	assert.InDelta(t, 1-math.Exp(-0.5), vout.GetSignal(1)[5], 1e-12)
+
+	s, err = Parse("testdata/simulations/qspice/rc-ac.qraw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ACAnalysis, s.GetType())
+	assert.True(t, s.Meta.Flags.hasFlag(Complex|Log))
+	assert.InDelta(t, 1000, s.GetXAxis()[4], 1e-9)
+}
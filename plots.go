@@ -0,0 +1,74 @@
+package ltspice
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Plots holds the plots of a raw file in the order they appear.
+type Plots []*SimData
+
+// ParseAll loads and parses all plots of a raw file. Some simulators, e.g. ngspice, write several
+// analyses into a single raw file, each with its own header and metadata.
+//
+// Plots of analyses this package does not support, e.g. the "Integrated Noise" plot of ngspice, are skipped.
+//
+// Example usage:
+//
+//	plots, err := ltspice.ParseAll("path/to/ngspice.raw")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	tran, ok := plots.ByType(ltspice.TransientAnalysis)
+func ParseAll(fileName string) (Plots, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseAllFromReader(file)
+}
+
+// ParseAllFromReader parses all plots from the provided io.Reader, see ParseAll.
+func ParseAllFromReader(reader io.Reader) (Plots, error) {
+	r := newHeaderReader(reader)
+	var plots Plots
+	for !r.atEOF() {
+		sim, err := parsePlot(r)
+		if errors.Is(err, ErrInvalidSimulationType) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		plots = append(plots, sim)
+	}
+	if len(plots) == 0 {
+		return nil, fmt.Errorf("%w: no supported plots found", ErrInvalidSimulationType)
+	}
+	return plots, nil
+}
+
+// ByType returns the first plot of the given simulation type.
+func (p Plots) ByType(typ SimType) (*SimData, bool) {
+	for _, sim := range p {
+		if sim.GetType() == typ {
+			return sim, true
+		}
+	}
+	return nil, false
+}
+
+// ByName returns the first plot with the given plot name, compared case-insensitively with the
+// name written in the raw file and the name of its simulation type.
+func (p Plots) ByName(name string) (*SimData, bool) {
+	for _, sim := range p {
+		if strings.EqualFold(sim.Meta.PlotName, name) || strings.EqualFold(sim.GetType().String(), name) {
+			return sim, true
+		}
+	}
+	return nil, false
+}
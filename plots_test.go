@@ -0,0 +1,94 @@
+package ltspice
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAll(t *testing.T) {
+	plots, err := ParseAll("testdata/simulations/ngspice/rc.raw")
+	require.NoError(t, err)
+	require.Len(t, plots, 2)
+	assert.Equal(t, ACAnalysis, plots[0].GetType())
+	assert.Equal(t, TransientAnalysis, plots[1].GetType())
+	assert.Equal(t, 51, plots[1].Meta.NoPoints)
+	assert.Equal(t, []string{"time", "v(in)", "v(out)", "i(v1)"}, variableNames(plots[1]))
+
+	tran, ok := plots.ByType(TransientAnalysis)
+	require.True(t, ok)
+	assert.Same(t, plots[1], tran)
+	assert.InDelta(t, 5e-3, tran.GetXAxis()[50], 1e-15)
+
+	ac, ok := plots.ByName("ac analysis")
+	require.True(t, ok)
+	assert.Same(t, plots[0], ac)
+
+	_, ok = plots.ByType(NoiseSpectralDensity)
+	assert.False(t, ok)
+	_, ok = plots.ByName("Integrated Noise")
+	assert.False(t, ok)
+}
+
+func TestParseAllASCII(t *testing.T) {
+	plots, err := ParseAll("testdata/simulations/ngspice/rc-ascii.raw")
+	require.NoError(t, err)
+	require.Len(t, plots, 2)
+	op, ok := plots.ByName("Operating Point")
+	require.True(t, ok)
+	assert.Equal(t, OperatingPoint, op.GetType())
+	assert.Equal(t, "Operating Point", op.Meta.PlotName)
+}
+
+func TestParseAllSkipsUnsupportedPlots(t *testing.T) {
+	raw := strings.Join([]string{
+		"Title: noise",
+		"Plotname: Noise Spectral Density Curves",
+		"Flags: real",
+		"No. Variables: 2",
+		"No. Points: 2",
+		"Variables:",
+		"\t0\tfrequency\tfrequency",
+		"\t1\tonoise_spectrum\tnotype",
+		"Values:",
+		" 0\t1.0e+01",
+		"\t2.0e-08",
+		" 1\t1.0e+02",
+		"\t1.5e-08",
+		"",
+		"Title: noise",
+		"Plotname: Integrated Noise",
+		"Flags: real",
+		"No. Variables: 2",
+		"No. Points: 1",
+		"Variables:",
+		"\t0\tinoise_total\tnotype",
+		"\t1\tonoise_total\tnotype",
+		"Values:",
+		" 0\t1.0e-06",
+		"\t2.0e-06",
+		"",
+	}, "\n")
+
+	plots, err := ParseAllFromReader(strings.NewReader(raw))
+	require.NoError(t, err)
+	require.Len(t, plots, 1)
+	assert.Equal(t, NoiseSpectralDensity, plots[0].GetType())
+	assert.Equal(t, []float64{2e-8, 1.5e-8}, plots[0].data["onoise_spectrum"])
+
+	_, err = ParseAllFromReader(strings.NewReader(raw[strings.Index(raw, "Title: noise\nPlotname: Integrated"):]))
+	assert.ErrorIs(t, err, ErrInvalidSimulationType)
+
+	_, err = ParseAllFromReader(strings.NewReader(raw[:len(raw)-20]))
+	assert.ErrorIs(t, err, ErrUnexpectedEndOfFile)
+}
+
+func variableNames(sim *SimData) []string {
+	var names []string
+	for _, v := range sim.GetVariables() {
+		names = append(names, v.Name)
+	}
+	return names
+}
@@ -0,0 +1,480 @@
+package ltspice
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// PowerOptions configures the power and ripple measurements of switching converters. The measurements
+// average over an integer number of switching periods at the end of the window, where a converter is
+// closest to its steady state.
+type PowerOptions struct {
+	// Period is the switching period in seconds.
+	Period float64
+	// Periods is the number of switching periods measured, defaults to all complete periods of the window.
+	Periods int
+	// Start and Stop limit the measurement to a window of the time axis. Zero values select the first and
+	// last point of each step.
+	Start, Stop float64
+	// Tolerance is the largest cycle-to-cycle change of SteadyState relative to the full scale of the
+	// trace, defaults to 1e-3.
+	Tolerance float64
+}
+
+// defaultSteadyStateTolerance is the default of PowerOptions.Tolerance.
+const defaultSteadyStateTolerance = 1e-3
+
+// steadyStateCycles is the number of consecutive converged cycles SteadyState requires.
+const steadyStateCycles = 3
+
+// Power holds the power of a port or device over an integer number of switching periods.
+type Power struct {
+	// Average is the mean power in W.
+	Average float64
+	// RMS is the RMS value of the instantaneous power in W.
+	RMS float64
+	// Peak is the largest magnitude of the instantaneous power in W.
+	Peak float64
+	// Start and Stop are the bounds of the measured periods.
+	Start, Stop float64
+}
+
+// Ripple holds the statistics of a trace over an integer number of switching periods.
+type Ripple struct {
+	Average, Min, Max float64
+	// PeakToPeak is Max - Min.
+	PeakToPeak float64
+	// RMS is the RMS value of the trace and ACRMS the RMS value of the ripple around the average.
+	RMS, ACRMS float64
+	// Start and Stop are the bounds of the measured periods.
+	Start, Stop float64
+}
+
+// ConductionMode is the operating mode of the inductor of a switching converter.
+type ConductionMode int
+
+const (
+	// ContinuousConduction is CCM, the inductor current never drops to zero, or it reverses as in
+	// forced CCM of synchronous converters.
+	ContinuousConduction ConductionMode = iota
+	// DiscontinuousConduction is DCM, the inductor current stays at zero for a part of each period.
+	DiscontinuousConduction
+	// BoundaryConduction is BCM, the inductor current touches zero at the end of each period.
+	BoundaryConduction
+)
+
+func (m ConductionMode) String() string {
+	return [...]string{"CCM", "DCM", "BCM"}[m]
+}
+
+// InductorCurrent holds the statistics and the conduction mode of an inductor current.
+type InductorCurrent struct {
+	Mode ConductionMode
+	Ripple
+	// ZeroFraction is the fraction of the periods in which the current is zero.
+	ZeroFraction float64
+}
+
+// PowerPort is a port of a transient analysis, given by the traces of its voltage and current.
+type PowerPort struct {
+	// Voltage is the name of a voltage trace, e.g. "V(out)", or the difference of two nodes written as
+	// "V(sw,out)", where "0" is the ground node.
+	Voltage string
+	// Current is the name of the trace of the current flowing into the port, e.g. "I(Rload)" or
+	// "Ix(U1:VIN)".
+	Current string
+	// ReverseCurrent negates the current, e.g. for the current of a source, which flows out of the port.
+	ReverseCurrent bool
+}
+
+// SteadyState holds the result of the steady-state detection of a trace.
+type SteadyState struct {
+	// Reached reports whether the last cycles of the window converged.
+	Reached bool
+	// Time is the start of the first cycle from which on all cycles are within the tolerance of the
+	// previous cycle. It is NaN if the steady state is not reached.
+	Time float64
+	// Deviations holds the change of each cycle relative to the previous cycle, relative to the full
+	// scale of the trace. The first cycle has no predecessor and is not included.
+	Deviations []float64
+}
+
+// Power returns the power of a port of a transient analysis, the product of its voltage and current,
+// over an integer number of switching periods. For stepped simulations a step index selects the step, the
+// first step is used if none is given.
+//
+// LTSpice writes the current of a two-terminal device as flowing into its first pin, so the power of a
+// resistor R1 between the nodes a and b is Power(PowerPort{Voltage: "V(a,b)", Current: "I(R1)"}) and
+// the power delivered by a source is negative.
+//
+// Example usage:
+//
+//	opts := ltspice.PowerOptions{Period: 1e-6, Periods: 20}
+//	p, err := simData.Power(ltspice.PowerPort{Voltage: "V(out)", Current: "I(Rload)"}, opts)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("output power %.3f W\n", p.Average)
+func (sim *SimData) Power(port PowerPort, opts PowerOptions, step ...int) (*Power, error) {
+	v, err := sim.voltage(port.Voltage, step...)
+	if err != nil {
+		return nil, err
+	}
+	i, err := sim.realSignal(port.Current, step...)
+	if err != nil {
+		return nil, err
+	}
+	sign := 1.0
+	if port.ReverseCurrent {
+		sign = -1
+	}
+	p := make([]float64, len(v))
+	for k := range p {
+		p[k] = sign * v[k] * i[k]
+	}
+	return sim.power(p, opts, step...)
+}
+
+// DeviceCurrents returns the current traces of a device, keyed by its terminal. LTSpice writes the
+// current of a two-terminal device as I(R1), which is keyed by the empty string, the currents into the
+// terminals of transistors as Ic(Q1), Ib(Q1) and Ie(Q1) or Id(M1), Ig(M1) and Is(M1), which are keyed by
+// "c", "b", "e", "d", "g" and "s", and the currents into the pins of subcircuits as Ix(U1:VIN), which is
+// keyed by the pin name "VIN". Device names are compared case-insensitively.
+func (sim *SimData) DeviceCurrents(device string) map[string]string {
+	currents := map[string]string{}
+	for _, v := range sim.Meta.Variables[1:] {
+		terminal, name, ok := parseDeviceCurrent(v.Name)
+		if !ok {
+			continue
+		}
+		if strings.EqualFold(name, device) {
+			currents[terminal] = v.Name
+		}
+	}
+	return currents
+}
+
+// parseDeviceCurrent splits the name of a current trace into the terminal and the device.
+func parseDeviceCurrent(name string) (string, string, bool) {
+	open := strings.IndexByte(name, '(')
+	if open < 1 || name[0] != 'I' && name[0] != 'i' || !strings.HasSuffix(name, ")") {
+		return "", "", false
+	}
+	terminal, device := strings.ToLower(name[1:open]), name[open+1:len(name)-1]
+	if terminal == "x" {
+		dev, pin, ok := cutLast(device, ":")
+		if !ok {
+			return "", "", false
+		}
+		return pin, dev, true
+	}
+	return terminal, device, true
+}
+
+// DevicePower returns the power dissipated by a device over an integer number of switching periods, the sum
+// of the node voltages times the currents into its terminals, see DeviceCurrents for the names of the
+// terminals. nodes maps the terminals to the voltages of the nodes they connect to, e.g.
+// {"d": "V(sw)", "g": "V(gate)", "s": "V(in)"}. Terminals which are not in nodes connect to ground.
+//
+// The current of a two-terminal device flows from its first to its second pin, whose nodes are keyed by
+// "1" and "2", so DevicePower("L1", {"1": "V(sw)", "2": "V(out)"}) is the power of the inductor L1.
+func (sim *SimData) DevicePower(device string, nodes map[string]string, opts PowerOptions, step ...int) (*Power, error) {
+	currents := sim.DeviceCurrents(device)
+	if len(currents) == 0 {
+		return nil, fmt.Errorf("%w: no currents of device %s", ErrTraceDoesNotExist, device)
+	}
+	// sign of the current into each terminal
+	signs := map[string]float64{}
+	for terminal := range currents {
+		signs[terminal] = 1
+	}
+	if branch, ok := currents[""]; ok {
+		// the current of a two-terminal device flows into pin 1 and out of pin 2
+		delete(currents, "")
+		currents["1"], currents["2"] = branch, branch
+		signs["1"], signs["2"] = 1, -1
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("device %s: no terminal is connected to a node", device)
+	}
+	for terminal := range nodes {
+		if _, ok := currents[terminal]; !ok {
+			return nil, fmt.Errorf("device %s has no terminal %q", device, terminal)
+		}
+	}
+
+	p := make([]float64, len(sim.GetXAxis(step...)))
+	for terminal, node := range nodes {
+		v, err := sim.voltage(node, step...)
+		if err != nil {
+			return nil, err
+		}
+		i, err := sim.realSignal(currents[terminal], step...)
+		if err != nil {
+			return nil, err
+		}
+		for k := range p {
+			p[k] += signs[terminal] * v[k] * i[k]
+		}
+	}
+	return sim.power(p, opts, step...)
+}
+
+// Efficiency returns the ratio of the output to the input power of a converter over an integer number of
+// switching periods. The magnitudes of the powers are used, so the sign convention of the currents does not
+// matter, e.g. the input can be PowerPort{Voltage: "V(in)", Current: "I(V1)"} with the current of the input
+// source V1.
+func (sim *SimData) Efficiency(input, output PowerPort, opts PowerOptions, step ...int) (float64, error) {
+	in, err := sim.Power(input, opts, step...)
+	if err != nil {
+		return 0, err
+	}
+	out, err := sim.Power(output, opts, step...)
+	if err != nil {
+		return 0, err
+	}
+	if in.Average == 0 {
+		return 0, errors.New("efficiency: the input power is zero")
+	}
+	return math.Abs(out.Average) / math.Abs(in.Average), nil
+}
+
+// Ripple measures the average, peak-to-peak and RMS ripple of a trace, e.g. the output voltage of a
+// converter, over an integer number of switching periods.
+func (sim *SimData) Ripple(name string, opts PowerOptions, step ...int) (*Ripple, error) {
+	y, err := sim.voltage(name, step...)
+	if err != nil {
+		return nil, err
+	}
+	x, y, err := sim.periods(y, opts, step...)
+	if err != nil {
+		return nil, err
+	}
+	return rippleOf(x, y), nil
+}
+
+// InductorCurrent measures an inductor current over an integer number of switching periods and detects the
+// conduction mode. The current counts as zero within 1% of its peak-to-peak value, a current which is zero
+// for more than 3% of the time is in DCM.
+func (sim *SimData) InductorCurrent(name string, opts PowerOptions, step ...int) (*InductorCurrent, error) {
+	y, err := sim.realSignal(name, step...)
+	if err != nil {
+		return nil, err
+	}
+	x, y, err := sim.periods(y, opts, step...)
+	if err != nil {
+		return nil, err
+	}
+	r := &InductorCurrent{Ripple: *rippleOf(x, y)}
+	// the current may be measured in either direction
+	sign := 1.0
+	if r.Average < 0 {
+		sign = -1
+	}
+	tol := 0.01 * r.PeakToPeak
+	var zero float64
+	for k := 1; k < len(x); k++ {
+		if math.Abs(y[k]) <= tol && math.Abs(y[k-1]) <= tol {
+			zero += x[k] - x[k-1]
+		}
+	}
+	r.ZeroFraction = zero / (r.Stop - r.Start)
+	low := math.Min(sign*r.Min, sign*r.Max)
+	switch {
+	case math.Abs(low) > tol:
+		r.Mode = ContinuousConduction
+	case r.ZeroFraction > 0.03:
+		r.Mode = DiscontinuousConduction
+	default:
+		r.Mode = BoundaryConduction
+	}
+	return r, nil
+}
+
+// SteadyState detects whether a trace, e.g. the output voltage of a converter, reached its steady state by
+// comparing the average, minimum and maximum of consecutive switching periods from the start of the window.
+// The steady state is reached when the last three cycles changed by less than the tolerance.
+func (sim *SimData) SteadyState(name string, opts PowerOptions, step ...int) (*SteadyState, error) {
+	if opts.Period <= 0 {
+		return nil, errors.New("steady state: the switching period must be positive")
+	}
+	tol := opts.Tolerance
+	if tol == 0 {
+		tol = defaultSteadyStateTolerance
+	}
+	y, err := sim.voltage(name, step...)
+	if err != nil {
+		return nil, err
+	}
+	x := sim.GetXAxis(step...)
+	start, stop, err := powerWindow(x, opts)
+	if err != nil {
+		return nil, err
+	}
+	n := int(math.Floor((stop-start)/opts.Period + 1e-9))
+	if n < 2 {
+		return nil, fmt.Errorf("steady state of %s: the window holds less than two periods", name)
+	}
+
+	cycles := make([]*Ripple, n)
+	for k := range cycles {
+		cx, cy := cycleWindow(x, y, start+float64(k)*opts.Period, start+float64(k+1)*opts.Period)
+		cycles[k] = rippleOf(cx, cy)
+	}
+	last := cycles[n-1]
+	scale := math.Max(math.Abs(last.Min), math.Abs(last.Max))
+	if scale == 0 {
+		scale = 1
+	}
+	s := &SteadyState{Time: math.NaN()}
+	for k := 1; k < n; k++ {
+		prev, cur := cycles[k-1], cycles[k]
+		d := math.Max(math.Abs(cur.Average-prev.Average), math.Max(math.Abs(cur.Min-prev.Min), math.Abs(cur.Max-prev.Max)))
+		s.Deviations = append(s.Deviations, d/scale)
+	}
+	first := len(s.Deviations)
+	for first > 0 && s.Deviations[first-1] < tol {
+		first--
+	}
+	// deviation k compares cycle k+1 to cycle k, so cycle first is the first cycle of the steady state
+	if len(s.Deviations)-first >= min(steadyStateCycles, len(s.Deviations)) {
+		s.Reached = true
+		s.Time = start + float64(first)*opts.Period
+	}
+	return s, nil
+}
+
+// power measures the instantaneous power p of a step.
+func (sim *SimData) power(p []float64, opts PowerOptions, step ...int) (*Power, error) {
+	x, p, err := sim.periods(p, opts, step...)
+	if err != nil {
+		return nil, err
+	}
+	r := rippleOf(x, p)
+	return &Power{Average: r.Average, RMS: r.RMS, Peak: math.Max(math.Abs(r.Min), math.Abs(r.Max)), Start: r.Start, Stop: r.Stop}, nil
+}
+
+// periods returns the part of the signal y of a step within the measured switching periods.
+func (sim *SimData) periods(y []float64, opts PowerOptions, step ...int) ([]float64, []float64, error) {
+	if opts.Period <= 0 {
+		return nil, nil, errors.New("power: the switching period must be positive")
+	}
+	if opts.Periods < 0 {
+		return nil, nil, errors.New("power: the number of periods must not be negative")
+	}
+	x := sim.GetXAxis(step...)
+	start, stop, err := powerWindow(x, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	n := opts.Periods
+	if n == 0 {
+		n = int(math.Floor((stop-start)/opts.Period + 1e-9))
+	}
+	a := stop - float64(n)*opts.Period
+	if n < 1 || a < start-1e-9*opts.Period {
+		return nil, nil, fmt.Errorf("power: the window [%g, %g] holds less than %d periods of %g s", start, stop, max(n, 1), opts.Period)
+	}
+	cx, cy := cycleWindow(x, y, a, stop)
+	return cx, cy, nil
+}
+
+// powerWindow returns the time window of a step with the x-axis x.
+func powerWindow(x []float64, opts PowerOptions) (float64, float64, error) {
+	if len(x) < 2 {
+		return 0, 0, errors.New("power: the step holds less than two points")
+	}
+	start, stop, err := xWindow(x, opts.Start, opts.Stop)
+	if err != nil {
+		return 0, 0, fmt.Errorf("power: %w", err)
+	}
+	return start, stop, nil
+}
+
+// cycleWindow returns the samples of (x, y) within [a, b] with the values at a and b interpolated. x must be
+// increasing.
+func cycleWindow(x, y []float64, a, b float64) ([]float64, []float64) {
+	lo := sort.Search(len(x), func(k int) bool { return x[k] > a })
+	hi := max(lo, sort.SearchFloat64s(x, b))
+	cx := append(append([]float64{a}, x[lo:hi]...), b)
+	cy := append(append([]float64{interpLinear(x, y, a)}, y[lo:hi]...), interpLinear(x, y, b))
+	return cx, cy
+}
+
+// rippleOf computes the statistics of a window using the trapezoidal rule.
+func rippleOf(x, y []float64) *Ripple {
+	r := &Ripple{Min: math.Inf(1), Max: math.Inf(-1), Start: x[0], Stop: x[len(x)-1]}
+	var sum, sq float64
+	for k := range y {
+		r.Min, r.Max = math.Min(r.Min, y[k]), math.Max(r.Max, y[k])
+		if k > 0 {
+			dx := x[k] - x[k-1]
+			sum += (y[k] + y[k-1]) / 2 * dx
+			sq += (y[k]*y[k] + y[k-1]*y[k-1]) / 2 * dx
+		}
+	}
+	span := r.Stop - r.Start
+	r.Average = sum / span
+	r.RMS = math.Sqrt(sq / span)
+	r.ACRMS = math.Sqrt(math.Max(0, sq/span-r.Average*r.Average))
+	r.PeakToPeak = r.Max - r.Min
+	return r
+}
+
+// voltage returns the signal of a step of a voltage trace or of the difference of two nodes written as
+// "V(a,b)". The ground node "0" has no trace.
+func (sim *SimData) voltage(expr string, step ...int) ([]float64, error) {
+	node := func(name string) ([]float64, error) {
+		name = strings.TrimSpace(name)
+		if name == "0" || name == "" {
+			return make([]float64, len(sim.GetXAxis(step...))), nil
+		}
+		return sim.realSignal("V("+name+")", step...)
+	}
+	if len(expr) > 3 && (expr[0] == 'V' || expr[0] == 'v') && expr[1] == '(' && strings.HasSuffix(expr, ")") {
+		if a, b, ok := strings.Cut(expr[2:len(expr)-1], ","); ok {
+			va, err := node(a)
+			if err != nil {
+				return nil, err
+			}
+			vb, err := node(b)
+			if err != nil {
+				return nil, err
+			}
+			out := make([]float64, len(va))
+			for k := range out {
+				out[k] = va[k] - vb[k]
+			}
+			return out, nil
+		}
+		return node(expr[2 : len(expr)-1])
+	}
+	if expr == "0" {
+		return node(expr)
+	}
+	return sim.realSignal(expr, step...)
+}
+
+// realSignal returns the signal of a step of a real trace of a transient analysis. Names are compared
+// case-insensitively if there is no exact match, as LTSpice changes the case of node names.
+func (sim *SimData) realSignal(name string, step ...int) ([]float64, error) {
+	if sim.Meta.Flags.hasFlag(Complex) {
+		return nil, fmt.Errorf("%w: power measurements require real data, got %s", ErrInvalidSimulationType, sim.GetType())
+	}
+	if _, ok := sim.data[name]; !ok {
+		for _, v := range sim.Meta.Variables {
+			if strings.EqualFold(v.Name, name) {
+				name = v.Name
+				break
+			}
+		}
+	}
+	trace, err := GetTrace[float64](sim, name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, name)
+	}
+	return trace.GetSignal(step...), nil
+}
@@ -0,0 +1,293 @@
+package ltspice
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const buckPeriod = 10e-6
+
+// triangle returns a periodic triangle which rises from lo to hi during the fraction rise of the switching
+// period and falls back to lo at the fraction fall, staying at lo for the rest of the period.
+func triangle(lo, hi, rise, fall float64) func(float64) float64 {
+	return func(tm float64) float64 {
+		phase := math.Mod(tm, buckPeriod) / buckPeriod
+		switch {
+		case phase < rise:
+			return lo + (hi-lo)*phase/rise
+		case phase < fall:
+			return hi - (hi-lo)*(phase-rise)/(fall-rise)
+		default:
+			return lo
+		}
+	}
+}
+
+// buckSim returns 1 ms of an idealized buck converter with a duty cycle of 0.4 switching at 100 kHz.
+func buckSim(t *testing.T) *SimData {
+	il := triangle(0.8, 1.2, 0.4, 1)
+	out := func(tm float64) float64 { return 4 + 0.01*math.Sin(2*math.Pi*tm/buckPeriod) }
+	on := func(tm float64) bool { return math.Mod(tm, buckPeriod) < 0.4*buckPeriod }
+	return waveformSim(t, 1e-3, map[string]func(float64) float64{
+		"V(in)": func(float64) float64 { return 10 },
+		"V(sw)": func(tm float64) float64 {
+			if on(tm) {
+				return 10
+			}
+			return 0
+		},
+		"V(out)": out,
+		"I(V1)": func(tm float64) float64 {
+			if on(tm) {
+				return -il(tm)
+			}
+			return 0
+		},
+		"I(L1)":    il,
+		"I(Rload)": func(tm float64) float64 { return out(tm) / 4.4 },
+		"I(L2)":    triangle(0, 1, 0.3, 0.6),
+		"I(L3)":    triangle(0, 1, 0.5, 1),
+		"I(L4)":    triangle(-0.2, 1, 0.5, 1),
+	})
+}
+
+func TestPower(t *testing.T) {
+	sim := buckSim(t)
+	opts := PowerOptions{Period: buckPeriod, Periods: 20}
+
+	in, err := sim.Power(PowerPort{Voltage: "V(in)", Current: "I(V1)"}, opts)
+	require.NoError(t, err)
+	// the sampled switching edges add a little to the ideal 4 W
+	assert.InDelta(t, -4, in.Average, 1e-2)
+	assert.InDelta(t, 12, in.Peak, 1e-2)
+	assert.InDelta(t, 1e-3-20*buckPeriod, in.Start, 1e-12)
+	assert.InDelta(t, 1e-3, in.Stop, 1e-12)
+
+	// the source current flows out of the port
+	reversed, err := sim.Power(PowerPort{Voltage: "V(in)", Current: "I(V1)", ReverseCurrent: true}, opts)
+	require.NoError(t, err)
+	assert.Equal(t, -in.Average, reversed.Average)
+
+	out, err := sim.Power(PowerPort{Voltage: "V(out)", Current: "I(Rload)"}, opts)
+	require.NoError(t, err)
+	want := (16 + 0.01*0.01/2) / 4.4
+	assert.InDelta(t, want, out.Average, 1e-6)
+	assert.InDelta(t, want, out.RMS, 1e-4)
+
+	eff, err := sim.Efficiency(PowerPort{Voltage: "V(in)", Current: "I(V1)"}, PowerPort{Voltage: "V(out)", Current: "I(Rload)"}, opts)
+	require.NoError(t, err)
+	assert.InDelta(t, want/4, eff, 3e-3)
+
+	// all complete periods of the window
+	all, err := sim.Power(PowerPort{Voltage: "V(out)", Current: "I(Rload)"}, PowerOptions{Period: buckPeriod, Start: 0.15e-3})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.15e-3, all.Start, 1e-12)
+	assert.InDelta(t, want, all.Average, 1e-6)
+}
+
+func TestDevicePower(t *testing.T) {
+	sim := buckSim(t)
+	opts := PowerOptions{Period: buckPeriod}
+	assert.Equal(t, map[string]string{"": "I(L1)"}, sim.DeviceCurrents("l1"))
+
+	port, err := sim.Power(PowerPort{Voltage: "V(sw,out)", Current: "I(L1)"}, opts)
+	require.NoError(t, err)
+	device, err := sim.DevicePower("L1", map[string]string{"1": "V(sw)", "2": "V(out)"}, opts)
+	require.NoError(t, err)
+	assert.InDelta(t, port.Average, device.Average, 1e-12)
+
+	// a terminal which is not connected is grounded
+	grounded, err := sim.DevicePower("L1", map[string]string{"1": "V(sw)"}, opts)
+	require.NoError(t, err)
+	port, err = sim.Power(PowerPort{Voltage: "V(sw,0)", Current: "I(L1)"}, opts)
+	require.NoError(t, err)
+	assert.InDelta(t, port.Average, grounded.Average, 1e-12)
+
+	_, err = sim.DevicePower("L1", map[string]string{"d": "V(sw)"}, opts)
+	assert.Error(t, err)
+	// a device without connected terminals has no power
+	_, err = sim.DevicePower("L1", nil, opts)
+	assert.Error(t, err)
+	_, err = sim.DevicePower("M1", nil, opts)
+	assert.ErrorIs(t, err, ErrTraceDoesNotExist)
+}
+
+func TestDevicePowerSubcircuit(t *testing.T) {
+	var tm, vin, vsw, iin, isw, ignd []float64
+	for k := 0; k <= 1000; k++ {
+		x := float64(k) * buckPeriod / 100
+		tm = append(tm, x)
+		vin = append(vin, 12)
+		vsw = append(vsw, 6+6*math.Sin(2*math.Pi*x/buckPeriod))
+		iin = append(iin, 0.5)
+		isw = append(isw, -0.4)
+		ignd = append(ignd, -0.1)
+	}
+	sim, err := newTableSim(TransientAnalysis, "subckt",
+		[]string{"time", "V(in)", "V(sw)", "Ix(U1:VIN)", "Ix(U1:SW)", "Ix(U1:GND)"},
+		[][]float64{tm, vin, vsw, iin, isw, ignd}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"VIN": "Ix(U1:VIN)", "SW": "Ix(U1:SW)", "GND": "Ix(U1:GND)"}, sim.DeviceCurrents("U1"))
+	p, err := sim.DevicePower("U1", map[string]string{"VIN": "V(in)", "SW": "V(sw)"}, PowerOptions{Period: buckPeriod})
+	require.NoError(t, err)
+	assert.InDelta(t, 12*0.5-6*0.4, p.Average, 1e-9)
+}
+
+func TestRipple(t *testing.T) {
+	sim := buckSim(t)
+	r, err := sim.Ripple("V(out)", PowerOptions{Period: buckPeriod, Periods: 10})
+	require.NoError(t, err)
+	assert.InDelta(t, 4, r.Average, 1e-9)
+	assert.InDelta(t, 0.02, r.PeakToPeak, 1e-6)
+	assert.InDelta(t, 0.01/math.Sqrt2, r.ACRMS, 1e-6)
+	assert.InDelta(t, math.Sqrt(16+0.01*0.01/2), r.RMS, 1e-6)
+
+	r, err = sim.Ripple("V(sw,out)", PowerOptions{Period: buckPeriod})
+	require.NoError(t, err)
+	assert.InDelta(t, 0, r.Average, 1e-2)
+}
+
+func TestInductorCurrent(t *testing.T) {
+	sim := buckSim(t)
+	opts := PowerOptions{Period: buckPeriod, Periods: 10}
+	for name, mode := range map[string]ConductionMode{
+		"I(L1)": ContinuousConduction,
+		"I(L2)": DiscontinuousConduction,
+		"I(L3)": BoundaryConduction,
+		"I(L4)": ContinuousConduction,
+	} {
+		l, err := sim.InductorCurrent(name, opts)
+		require.NoError(t, err)
+		assert.Equal(t, mode, l.Mode, name)
+	}
+
+	l, err := sim.InductorCurrent("I(L1)", opts)
+	require.NoError(t, err)
+	assert.InDelta(t, 1, l.Average, 1e-6)
+	assert.InDelta(t, 0.4, l.PeakToPeak, 1e-6)
+	assert.InDelta(t, 0.4/(2*math.Sqrt(3)), l.ACRMS, 5e-5)
+	assert.Zero(t, l.ZeroFraction)
+
+	l, err = sim.InductorCurrent("I(L2)", opts)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.4, l.ZeroFraction, 1e-2)
+	assert.Equal(t, "DCM", l.Mode.String())
+}
+
+func TestSteadyState(t *testing.T) {
+	sim := waveformSim(t, 1e-3, map[string]func(float64) float64{
+		"V(fast)": func(tm float64) float64 {
+			return 5*(1-math.Exp(-tm/50e-6)) + 0.05*math.Sin(2*math.Pi*tm/buckPeriod)
+		},
+		"V(slow)": func(tm float64) float64 { return 5 * (1 - math.Exp(-tm/1e-3)) },
+	})
+
+	s, err := sim.SteadyState("V(fast)", PowerOptions{Period: buckPeriod})
+	require.NoError(t, err)
+	assert.True(t, s.Reached)
+	assert.Len(t, s.Deviations, 99)
+	assert.Greater(t, s.Time, 200e-6)
+	assert.Less(t, s.Time, 350e-6)
+	for k, d := range s.Deviations {
+		if float64(k)*buckPeriod >= s.Time {
+			assert.Less(t, d, 1e-3)
+		}
+	}
+
+	// a looser tolerance is reached earlier
+	loose, err := sim.SteadyState("V(fast)", PowerOptions{Period: buckPeriod, Tolerance: 1e-2})
+	require.NoError(t, err)
+	assert.Less(t, loose.Time, s.Time)
+
+	s, err = sim.SteadyState("V(slow)", PowerOptions{Period: buckPeriod})
+	require.NoError(t, err)
+	assert.False(t, s.Reached)
+	assert.True(t, math.IsNaN(s.Time))
+}
+
+func TestPowerStepped(t *testing.T) {
+	sim, err := Parse("testdata/simulations/stepped/rc/rc.raw")
+	require.NoError(t, err)
+	opts := PowerOptions{Period: 1e-3}
+	for step := 0; step < sim.GetSteps(); step++ {
+		source, err := sim.Power(PowerPort{Voltage: "V(n001)", Current: "I(V1)"}, opts, step)
+		require.NoError(t, err)
+		// R1 is connected from n002 to n001
+		r, err := sim.DevicePower("R1", map[string]string{"1": "V(n002)", "2": "V(n001)"}, opts, step)
+		require.NoError(t, err)
+		c, err := sim.DevicePower("C1", map[string]string{"1": "V(n002)"}, opts, step)
+		require.NoError(t, err)
+
+		// the source delivers the power the resistor dissipates and the capacitor stores
+		assert.Less(t, source.Average, 0.0)
+		assert.Greater(t, r.Average, 0.0)
+		assert.InDelta(t, 0, source.Average+r.Average+c.Average, 2e-2*r.Average)
+	}
+}
+
+func TestPowerLM741(t *testing.T) {
+	sim, err := Parse("testdata/simulations/trans/LM741/LM741.raw")
+	require.NoError(t, err)
+	opts := PowerOptions{Period: 1e-3, Periods: 5}
+
+	// R1 N018 4 1K, node names are matched case-insensitively
+	p, err := sim.Power(PowerPort{Voltage: "V(N018,4)", Current: "I(R1)"}, opts)
+	require.NoError(t, err)
+	i, err := sim.Ripple("I(R1)", opts)
+	require.NoError(t, err)
+	assert.InEpsilon(t, 1e3*i.RMS*i.RMS, p.Average, 1e-2)
+
+	// Q19 4 N007 N012 0 PN
+	assert.Equal(t, map[string]string{"c": "Ic(Q19)", "b": "Ib(Q19)", "e": "Ie(Q19)"}, sim.DeviceCurrents("q19"))
+	q, err := sim.DevicePower("Q19", map[string]string{"c": "V(4)", "b": "V(n007)", "e": "V(n012)"}, opts)
+	require.NoError(t, err)
+	assert.Greater(t, q.Average, 0.0)
+}
+
+func TestPowerErrors(t *testing.T) {
+	sim := buckSim(t)
+	_, err := sim.Power(PowerPort{Voltage: "V(out)", Current: "I(Rload)"}, PowerOptions{})
+	assert.Error(t, err)
+	_, err = sim.Power(PowerPort{Voltage: "V(out)", Current: "I(Rload)"}, PowerOptions{Period: buckPeriod, Periods: 200})
+	assert.Error(t, err)
+	_, err = sim.Power(PowerPort{Voltage: "V(missing)", Current: "I(Rload)"}, PowerOptions{Period: buckPeriod})
+	assert.ErrorIs(t, err, ErrTraceDoesNotExist)
+	_, err = sim.Ripple("V(out,missing)", PowerOptions{Period: buckPeriod})
+	assert.ErrorIs(t, err, ErrTraceDoesNotExist)
+	_, err = sim.SteadyState("V(out)", PowerOptions{Period: 0.6e-3})
+	assert.Error(t, err)
+	// a stop before the start is not ignored
+	_, err = sim.SteadyState("V(out)", PowerOptions{Period: buckPeriod, Start: 0.5e-3, Stop: 0.2e-3})
+	assert.ErrorContains(t, err, "does not overlap")
+
+	// a step index out of range
+	stepped, err := Parse("testdata/simulations/stepped/rc/rc.raw")
+	require.NoError(t, err)
+	opts := PowerOptions{Period: 1e-3}
+	_, err = stepped.Power(PowerPort{Voltage: "V(n001)", Current: "I(V1)"}, opts, 9)
+	assert.Error(t, err)
+	_, err = stepped.Ripple("V(n002)", opts, 9)
+	assert.Error(t, err)
+	_, err = stepped.SteadyState("V(n002)", opts, 9)
+	assert.Error(t, err)
+
+	ac, err := Parse("testdata/simulations/ac/low-pass/low-pass-filter.raw")
+	require.NoError(t, err)
+	_, err = ac.Ripple("V(n002)", PowerOptions{Period: 1})
+	assert.ErrorIs(t, err, ErrInvalidSimulationType)
+}
+
+func TestCycleWindow(t *testing.T) {
+	x := []float64{0, 1, 2, 3, 4}
+	y := []float64{0, 10, 20, 30, 40}
+	cx, cy := cycleWindow(x, y, 0.5, 3)
+	assert.Equal(t, []float64{0.5, 1, 2, 3}, cx)
+	assert.Equal(t, []float64{5, 10, 20, 30}, cy)
+	cx, cy = cycleWindow(x, y, 1, 1.5)
+	assert.Equal(t, []float64{1, 1.5}, cx)
+	assert.Equal(t, []float64{10, 15}, cy)
+}
@@ -0,0 +1,319 @@
+package ltspice
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Interpolation selects the method used to evaluate a trace between its samples.
+type Interpolation int
+
+const (
+	// LinearInterpolation connects adjacent samples with straight lines.
+	LinearInterpolation Interpolation = iota
+	// CubicInterpolation uses a natural cubic spline through all samples.
+	CubicInterpolation
+	// SincInterpolation uses a Lanczos windowed sinc kernel with a cutoff at the Nyquist frequency of the
+	// target grid. It acts as an anti-aliasing filter when the trace is downsampled.
+	SincInterpolation
+)
+
+func (i Interpolation) String() string {
+	return [...]string{"linear", "cubic", "sinc"}[i]
+}
+
+const (
+	// defaultSincLobes is the number of lobes on each side of the sinc kernel.
+	defaultSincLobes = 4
+	// sincOversampling is the minimum ratio of the target grid spacing to the spacing of the dense grid
+	// the kernel is applied to.
+	sincOversampling = 8
+)
+
+// ResampleOptions configures Resample.
+type ResampleOptions struct {
+	Method Interpolation
+	// Step is the spacing of the uniform target grid. If it is zero, Points is used.
+	Step float64
+	// Points is the number of points of the target grid per step. If both Step and Points are zero,
+	// each step keeps its number of points.
+	Points int
+	// Start and Stop limit the target grid to a window of the x-axis. Zero values select the first and
+	// last point of each step.
+	Start, Stop float64
+	// Lobes is the number of lobes on each side of the sinc kernel, defaults to 4.
+	Lobes int
+}
+
+// Resample returns a copy of the simulation whose traces are interpolated on a uniform grid. LTSpice
+// adapts the time step of transient analyses, so the x-axis of a raw file is usually not uniform, while
+// most signal processing requires uniformly spaced samples.
+//
+// Each step of a stepped simulation is resampled on its own. The metadata, including the operating point,
+// is preserved, except for the log flag of logarithmic sweeps, as the target grid is linear.
+//
+// Example usage:
+//
+//	uniform, err := simData.Resample(ltspice.ResampleOptions{Method: ltspice.CubicInterpolation, Step: 1e-6})
+func (sim *SimData) Resample(opts ResampleOptions) (*SimData, error) {
+	if opts.Step < 0 || opts.Points < 0 || opts.Lobes < 0 {
+		return nil, errors.New("resample: step, points and lobes must not be negative")
+	}
+	if opts.Method < LinearInterpolation || opts.Method > SincInterpolation {
+		return nil, fmt.Errorf("resample: unknown interpolation method %d", opts.Method)
+	}
+
+	grids := make([][]float64, sim.steps.count)
+	points := 0
+	for s := range grids {
+		grid, err := resampleGrid(sim.GetXAxis(s), opts)
+		if err != nil {
+			return nil, fmt.Errorf("resample step %d: %w", s, err)
+		}
+		grids[s] = grid
+		points += len(grid)
+	}
+
+	out := sim.withVariables(sim.Meta.Variables)
+	out.Meta.NoPoints = points
+	// the target grid is linear, also for logarithmic AC and noise sweeps
+	out.Meta.Flags.clearFlag(Log)
+	out.steps = &steps{count: sim.steps.count, offsets: make([]int, 0, sim.steps.count)}
+	out.stepPoints = len(grids[0])
+	offset := 0
+	for _, grid := range grids {
+		out.steps.offsets = append(out.steps.offsets, offset)
+		offset += len(grid)
+	}
+
+	for _, v := range sim.Meta.Variables {
+		if sim.Meta.Flags.hasFlag(Complex) {
+			out.complexData[v.Name] = resampleSteps(sim, sim.complexData[v.Name], v.Name == sim.xAxisLabel, grids, points, opts)
+		} else {
+			out.data[v.Name] = resampleSteps(sim, sim.data[v.Name], v.Name == sim.xAxisLabel, grids, points, opts)
+		}
+	}
+	return out, nil
+}
+
+func resampleSteps[T float64 | complex128](sim *SimData, data []T, isXAxis bool, grids [][]float64, points int, opts ResampleOptions) []T {
+	out := make([]T, 0, points)
+	for s, grid := range grids {
+		if isXAxis {
+			for _, x := range grid {
+				var v T
+				switch p := any(&v).(type) {
+				case *float64:
+					*p = x
+				case *complex128:
+					*p = complex(x, 0)
+				}
+				out = append(out, v)
+			}
+			continue
+		}
+		start, end := sim.stepBounds(s)
+		out = append(out, interpolate(sim.GetXAxis(s), data[start:end], grid, opts)...)
+	}
+	return out
+}
+
+// Resample interpolates the signal of the given step at the points of grid. x is the x-axis of the step,
+// see SimData.GetXAxis, and must be increasing.
+//
+// Example usage:
+//
+//	grid := []float64{0, 1e-6, 2e-6}
+//	values := trace.Resample(simData.GetXAxis(step), grid, ltspice.ResampleOptions{}, step)
+func (t *Trace[T]) Resample(x, grid []float64, opts ResampleOptions, step ...int) []T {
+	return interpolate(x, t.GetSignal(step...), grid, opts)
+}
+
+// resampleGrid returns the uniform target grid for a step with the x-axis x.
+func resampleGrid(x []float64, opts ResampleOptions) ([]float64, error) {
+	if len(x) == 0 {
+		return nil, errors.New("empty x-axis")
+	}
+	lo, hi, err := xWindow(x, opts.Start, opts.Stop)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case opts.Step > 0:
+		n := int(math.Floor((hi-lo)/opts.Step+1e-9)) + 1
+		grid := make([]float64, n)
+		for i := range grid {
+			grid[i] = lo + float64(i)*opts.Step
+		}
+		return grid, nil
+	case opts.Points > 0:
+		return linspace(lo, hi, opts.Points), nil
+	default:
+		return linspace(lo, hi, len(x)), nil
+	}
+}
+
+func linspace(lo, hi float64, n int) []float64 {
+	grid := make([]float64, n)
+	if n == 1 {
+		grid[0] = lo
+		return grid
+	}
+	for i := range grid {
+		grid[i] = lo + (hi-lo)*float64(i)/float64(n-1)
+	}
+	return grid
+}
+
+// interpolate evaluates (x, y) at the points of grid. Complex values are interpolated
+// component-wise.
+func interpolate[T float64 | complex128](x []float64, y []T, grid []float64, opts ResampleOptions) []T {
+	switch v := any(y).(type) {
+	case []float64:
+		return any(interpolateReal(x, v, grid, opts)).([]T)
+	case []complex128:
+		re, im := make([]float64, len(v)), make([]float64, len(v))
+		for i, c := range v {
+			re[i], im[i] = real(c), imag(c)
+		}
+		re, im = interpolateReal(x, re, grid, opts), interpolateReal(x, im, grid, opts)
+		out := make([]complex128, len(grid))
+		for i := range out {
+			out[i] = complex(re[i], im[i])
+		}
+		return any(out).([]T)
+	}
+	return nil
+}
+
+func interpolateReal(x, y, grid []float64, opts ResampleOptions) []float64 {
+	out := make([]float64, len(grid))
+	switch opts.Method {
+	case CubicInterpolation:
+		spline := newCubicSpline(x, y)
+		for i, xq := range grid {
+			out[i] = spline.at(xq)
+		}
+	case SincInterpolation:
+		lobes := opts.Lobes
+		if lobes == 0 {
+			lobes = defaultSincLobes
+		}
+		return sincResample(x, y, grid, lobes)
+	default:
+		for i, xq := range grid {
+			out[i] = interpLinear(x, y, xq)
+		}
+	}
+	return out
+}
+
+// cubicSpline is a natural cubic spline. Duplicate x values, which LTSpice writes at discontinuities,
+// are collapsed to the last sample.
+type cubicSpline struct {
+	x, y, m []float64 // m holds the second derivatives
+}
+
+func newCubicSpline(x, y []float64) *cubicSpline {
+	s := &cubicSpline{}
+	for i := range x {
+		if n := len(s.x); n > 0 && x[i] <= s.x[n-1] {
+			s.y[n-1] = y[i]
+			continue
+		}
+		s.x = append(s.x, x[i])
+		s.y = append(s.y, y[i])
+	}
+	n := len(s.x)
+	s.m = make([]float64, n)
+	if n < 3 {
+		return s
+	}
+	// tridiagonal system for the second derivatives with m[0] = m[n-1] = 0
+	c := make([]float64, n)
+	d := make([]float64, n)
+	for i := 1; i < n-1; i++ {
+		h0, h1 := s.x[i]-s.x[i-1], s.x[i+1]-s.x[i]
+		rhs := 6 * ((s.y[i+1]-s.y[i])/h1 - (s.y[i]-s.y[i-1])/h0)
+		diag := 2*(h0+h1) - h0*c[i-1]
+		c[i] = h1 / diag
+		d[i] = (rhs - h0*d[i-1]) / diag
+	}
+	for i := n - 2; i > 0; i-- {
+		s.m[i] = d[i] - c[i]*s.m[i+1]
+	}
+	return s
+}
+
+func (s *cubicSpline) at(xq float64) float64 {
+	n := len(s.x)
+	switch {
+	case n == 0:
+		return 0
+	case xq <= s.x[0]:
+		return s.y[0]
+	case xq >= s.x[n-1]:
+		return s.y[n-1]
+	}
+	i := sort.SearchFloat64s(s.x, xq)
+	if s.x[i] == xq {
+		return s.y[i]
+	}
+	h := s.x[i] - s.x[i-1]
+	a, b := (s.x[i]-xq)/h, (xq-s.x[i-1])/h
+	return a*s.y[i-1] + b*s.y[i] + ((a*a*a-a)*s.m[i-1]+(b*b*b-b)*s.m[i])*h*h/6
+}
+
+// sincResample evaluates the Lanczos windowed sinc reconstruction of (x, y) at the points of the uniform grid.
+// The non-uniform samples are first interpolated with a cubic spline onto a dense uniform grid, at least
+// sincOversampling times finer than the target grid and as fine as the average sample spacing, which is then
+// low-pass filtered with the kernel. The result is normalized by the sum of the kernel weights, so constant
+// signals are preserved at the edges as well.
+func sincResample(x, y, grid []float64, lobes int) []float64 {
+	if len(grid) < 2 || len(x) < 2 {
+		out := make([]float64, len(grid))
+		for i, xq := range grid {
+			out[i] = interpLinear(x, y, xq)
+		}
+		return out
+	}
+	period := math.Abs(grid[1] - grid[0])
+	lo, hi := xRange(x)
+	h := math.Min(period/sincOversampling, (hi-lo)/float64(len(x)-1))
+
+	spline := newCubicSpline(x, y)
+	dense := make([]float64, int((hi-lo)/h)+1)
+	for k := range dense {
+		dense[k] = spline.at(lo + float64(k)*h)
+	}
+
+	support := float64(lobes) * period
+	out := make([]float64, len(grid))
+	for i, xq := range grid {
+		k0 := max(0, int(math.Ceil((xq-support-lo)/h)))
+		k1 := min(len(dense)-1, int(math.Floor((xq+support-lo)/h)))
+		var sum, weights float64
+		for k := k0; k <= k1; k++ {
+			u := (lo + float64(k)*h - xq) / period
+			w := sinc(u) * sinc(u/float64(lobes))
+			sum += w * dense[k]
+			weights += w
+		}
+		if weights <= 0 {
+			out[i] = spline.at(xq)
+			continue
+		}
+		out[i] = sum / weights
+	}
+	return out
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
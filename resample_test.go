@@ -0,0 +1,169 @@
+package ltspice
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jitteredSine returns two steps of a 1 kHz sine sampled on a non-uniform time axis.
+func jitteredSine(t *testing.T) *SimData {
+	rng := rand.New(rand.NewSource(1))
+	var x, y []float64
+	for step := 1; step <= 2; step++ {
+		tm := 0.0
+		for tm < 2e-3 {
+			x = append(x, tm)
+			y = append(y, float64(step)*math.Sin(2*math.Pi*1e3*tm))
+			tm += 5e-6 + 10e-6*rng.Float64()
+		}
+		x = append(x, 2e-3)
+		y = append(y, float64(step)*math.Sin(2*math.Pi*2))
+	}
+	sim, err := newTableSim(TransientAnalysis, "sine", []string{"time", "V(out)"}, [][]float64{x, y}, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, sim.GetSteps())
+	return sim
+}
+
+func TestResample(t *testing.T) {
+	sim := jitteredSine(t)
+	sim.op = &SimData{}
+
+	tests := map[Interpolation]struct {
+		tol float64
+		// edge is the number of points at the start and end of each step which are not checked
+		edge int
+	}{
+		LinearInterpolation: {tol: 2e-3},
+		CubicInterpolation:  {tol: 1e-5},
+		// the sinc kernel is truncated within Lobes points of the edges
+		SincInterpolation: {tol: 2e-4, edge: defaultSincLobes},
+	}
+	for method, tt := range tests {
+		t.Run(method.String(), func(t *testing.T) {
+			out, err := sim.Resample(ResampleOptions{Method: method, Step: 1e-5})
+			require.NoError(t, err)
+			assert.Equal(t, sim.Meta.Title, out.Meta.Title)
+			assert.Equal(t, sim.Meta.Flags, out.Meta.Flags)
+			assert.Same(t, sim.op, out.op)
+			require.Equal(t, 2, out.GetSteps())
+			assert.Equal(t, 402, out.Meta.NoPoints)
+
+			vout, err := GetTrace[float64](out, "V(out)")
+			require.NoError(t, err)
+			for step := 0; step < 2; step++ {
+				x := out.GetXAxis(step)
+				require.Len(t, x, 201)
+				assert.InDelta(t, 2e-3, x[200], 1e-12)
+				for i, v := range vout.GetSignal(step) {
+					assert.InDelta(t, 1e-5*float64(i), x[i], 1e-12)
+					if i < tt.edge || i >= len(x)-tt.edge {
+						continue
+					}
+					assert.InDelta(t, float64(step+1)*math.Sin(2*math.Pi*1e3*x[i]), v, tt.tol, "step %d, t=%g", step, x[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSincResampleConstant(t *testing.T) {
+	x := []float64{0, 0.1, 0.15, 0.4, 0.7, 1}
+	y := []float64{2, 2, 2, 2, 2, 2}
+	for _, v := range sincResample(x, y, linspace(0, 1, 11), defaultSincLobes) {
+		assert.InDelta(t, 2, v, 1e-12)
+	}
+}
+
+func TestResampleWindow(t *testing.T) {
+	sim := jitteredSine(t)
+	out, err := sim.Resample(ResampleOptions{Points: 11, Start: 0.5e-3, Stop: 1e-3})
+	require.NoError(t, err)
+	assert.Equal(t, 22, out.Meta.NoPoints)
+	x := out.GetXAxis(1)
+	require.Len(t, x, 11)
+	assert.InDelta(t, 0.5e-3, x[0], 1e-15)
+	assert.InDelta(t, 0.55e-3, x[1], 1e-15)
+	assert.InDelta(t, 1e-3, x[10], 1e-15)
+
+	// the window is clipped to the x-axis
+	out, err = sim.Resample(ResampleOptions{Step: 1e-4, Start: 1.5e-3, Stop: 1})
+	require.NoError(t, err)
+	assert.Len(t, out.GetXAxis(), 6)
+
+	// a zero bound selects the first or last point
+	out, err = sim.Resample(ResampleOptions{Step: 1e-4, Start: 1.5e-3})
+	require.NoError(t, err)
+	x = out.GetXAxis()
+	assert.Len(t, x, 6)
+	assert.InDelta(t, 2e-3, x[5], 1e-15)
+	out, err = sim.Resample(ResampleOptions{Step: 1e-4, Stop: 0.5e-3})
+	require.NoError(t, err)
+	x = out.GetXAxis()
+	assert.Len(t, x, 6)
+	assert.Equal(t, 0.0, x[0])
+
+	_, err = sim.Resample(ResampleOptions{Start: 1, Stop: 2})
+	assert.Error(t, err)
+	_, err = sim.Resample(ResampleOptions{Start: 1})
+	assert.Error(t, err)
+	_, err = sim.Resample(ResampleOptions{Step: -1})
+	assert.Error(t, err)
+	_, err = sim.Resample(ResampleOptions{Method: Interpolation(7)})
+	assert.Error(t, err)
+}
+
+func TestResampleFile(t *testing.T) {
+	sim, err := Parse("testdata/simulations/stepped/rc/rc.raw")
+	require.NoError(t, err)
+	out, err := sim.Resample(ResampleOptions{Method: CubicInterpolation, Points: 100})
+	require.NoError(t, err)
+	assert.Equal(t, sim.GetSteps(), out.GetSteps())
+	assert.Equal(t, 100*sim.GetSteps(), out.Meta.NoPoints)
+	assert.Equal(t, variableNames(sim), variableNames(out))
+
+	// without a target the number of points is kept
+	ac, err := Parse("testdata/simulations/ac/low-pass/low-pass-filter.raw")
+	require.NoError(t, err)
+	out, err = ac.Resample(ResampleOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, ac.Meta.NoPoints, out.Meta.NoPoints)
+	assert.Equal(t, ac.GetXAxis()[0], out.GetXAxis()[0])
+	assert.Equal(t, ac.complexData["V(n002)"][0], out.complexData["V(n002)"][0])
+
+	// the sweep is logarithmic, the target grid is not
+	loop, err := Parse("testdata/simulations/ac/Loop-Gain/LoopGain.raw")
+	require.NoError(t, err)
+	require.True(t, loop.Meta.Flags.hasFlag(Log))
+	out, err = loop.Resample(ResampleOptions{Points: 100})
+	require.NoError(t, err)
+	assert.False(t, out.Meta.Flags.hasFlag(Log))
+	assert.True(t, out.Meta.Flags.hasFlag(Complex))
+
+	lm741, err := Parse("testdata/simulations/trans/LM741/LM741.raw")
+	require.NoError(t, err)
+	out, err = lm741.Resample(ResampleOptions{Start: 0.005, Points: 100})
+	require.NoError(t, err)
+	x := out.GetXAxis()
+	assert.Equal(t, 0.005, x[0])
+	assert.Equal(t, lm741.GetXAxis()[lm741.Meta.NoPoints-1], x[99])
+}
+
+func TestTraceResample(t *testing.T) {
+	x := []float64{0, 1, 3}
+	trace := &Trace[complex128]{s: &steps{count: 1, offsets: []int{0}}, Data: []complex128{0, 1i, 3 + 3i}}
+	got := trace.Resample(x, []float64{0.5, 2}, ResampleOptions{})
+	assert.Equal(t, []complex128{0.5i, 1.5 + 2i}, got)
+}
+
+func TestCubicSplineDuplicates(t *testing.T) {
+	s := newCubicSpline([]float64{0, 1, 1, 2}, []float64{0, 1, 5, 5})
+	assert.Equal(t, []float64{0, 1, 2}, s.x)
+	assert.Equal(t, 5.0, s.at(1))
+	assert.Equal(t, 0.0, s.at(-1))
+	assert.Equal(t, 5.0, s.at(3))
+}
@@ -0,0 +1,118 @@
+package ltspice
+
+import (
+	"fmt"
+)
+
+// SelectTraces returns a copy of the simulation containing only the x-axis and the given traces.
+// The trace data is shared with the original simulation.
+// If a trace does not exist, ErrTraceDoesNotExist is returned.
+//
+// Example usage:
+//
+//	out, err := simData.SelectTraces("V(out)", "I(R1)")
+func (sim *SimData) SelectTraces(names ...string) (*SimData, error) {
+	byName := make(map[string]Variable, len(sim.Meta.Variables))
+	for _, v := range sim.Meta.Variables {
+		byName[v.Name] = v
+	}
+
+	vars := []Variable{sim.Meta.Variables[0]}
+	for _, name := range names {
+		v, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrTraceDoesNotExist, name)
+		}
+		if v.Name == sim.xAxisLabel {
+			continue
+		}
+		v.order = len(vars)
+		vars = append(vars, v)
+	}
+
+	out := sim.withVariables(vars)
+	for _, v := range vars {
+		if sim.Meta.Flags.hasFlag(Complex) {
+			out.complexData[v.Name] = sim.complexData[v.Name]
+		} else {
+			out.data[v.Name] = sim.data[v.Name]
+		}
+	}
+	return out, nil
+}
+
+// SelectSteps returns a copy of the simulation containing only the given steps in the given order.
+// The data of the selected steps is copied.
+func (sim *SimData) SelectSteps(indices ...int) (*SimData, error) {
+	for _, s := range indices {
+		if s < 0 || s >= sim.steps.count {
+			return nil, fmt.Errorf("step %d out of range, the simulation has %d steps", s, sim.steps.count)
+		}
+	}
+
+	out := sim.withVariables(sim.Meta.Variables)
+	out.steps = &steps{count: len(indices), offsets: make([]int, 0, len(indices))}
+	points := 0
+	for _, s := range indices {
+		out.steps.offsets = append(out.steps.offsets, points)
+		start, end := sim.stepBounds(s)
+		points += end - start
+	}
+	out.Meta.NoPoints = points
+	if out.steps.count <= 1 {
+		out.Meta.Flags.clearFlag(Stepped)
+	}
+	if sim.op != nil && sim.op.steps.count > 1 {
+		// a stepped operating point has one point per step
+		out.op, _ = sim.op.SelectSteps(indices...)
+	}
+
+	for _, v := range sim.Meta.Variables {
+		if sim.Meta.Flags.hasFlag(Complex) {
+			out.complexData[v.Name] = selectSteps(sim, sim.complexData[v.Name], indices, points)
+		} else {
+			out.data[v.Name] = selectSteps(sim, sim.data[v.Name], indices, points)
+		}
+	}
+	return out, nil
+}
+
+func selectSteps[T float64 | complex128](sim *SimData, data []T, indices []int, points int) []T {
+	out := make([]T, 0, points)
+	for _, s := range indices {
+		start, end := sim.stepBounds(s)
+		out = append(out, data[start:end]...)
+	}
+	return out
+}
+
+// withVariables returns an empty copy of the simulation with a copy of its metadata
+// describing the given variables.
+func (sim *SimData) withVariables(vars []Variable) *SimData {
+	meta := *sim.Meta
+	meta.Variables = vars
+	meta.NoVariables = len(vars)
+	out := &SimData{
+		Meta:       &meta,
+		xAxisLabel: sim.xAxisLabel,
+		steps:      sim.steps,
+		stepPoints: sim.stepPoints,
+		op:         sim.op,
+	}
+	if sim.Meta.Flags.hasFlag(Complex) {
+		out.complexData = make(map[string][]complex128, len(vars))
+	} else {
+		out.data = make(map[string][]float64, len(vars))
+	}
+	return out
+}
+
+// stepBounds returns the start and end index of the given step in the flat data.
+func (sim *SimData) stepBounds(step int) (int, int) {
+	start := sim.steps.offsets[step]
+	end := sim.Meta.NoPoints
+	if step+1 < len(sim.steps.offsets) {
+		end = sim.steps.offsets[step+1]
+	}
+	return start, end
+}
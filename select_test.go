@@ -0,0 +1,62 @@
+package ltspice
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectTraces(t *testing.T) {
+	sim, err := Parse("testdata/simulations/trans/stepped2/TRAN-STEP.raw")
+	require.NoError(t, err)
+
+	out, err := sim.SelectTraces("V(out)", "time", "I(R1)")
+	require.NoError(t, err)
+	require.Len(t, out.GetVariables(), 3)
+	assert.Equal(t, "time", out.GetVariables()[0].Name)
+	assert.Equal(t, "V(out)", out.GetVariables()[1].Name)
+	assert.Equal(t, "I(R1)", out.GetVariables()[2].Name)
+	assert.Equal(t, 3, out.Meta.NoVariables)
+	assert.Equal(t, sim.GetSteps(), out.GetSteps())
+	assert.Equal(t, sim.GetXAxis(2), out.GetXAxis(2))
+	assert.Equal(t, 6, sim.Meta.NoVariables, "the original simulation must not be modified")
+
+	_, err = out.SelectTraces("V(in)")
+	assert.True(t, errors.Is(err, ErrTraceDoesNotExist))
+}
+
+func TestSelectSteps(t *testing.T) {
+	sim, err := Parse("testdata/simulations/trans/stepped2/TRAN-STEP.raw")
+	require.NoError(t, err)
+
+	out, err := sim.SelectSteps(3, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, out.GetSteps())
+	assert.Equal(t, sim.GetXAxis(3), out.GetXAxis(0))
+	assert.Equal(t, sim.GetXAxis(1), out.GetXAxis(1))
+	assert.Equal(t, len(sim.GetXAxis(3))+len(sim.GetXAxis(1)), out.Meta.NoPoints)
+	assert.True(t, out.Meta.Flags.hasFlag(Stepped))
+
+	single, err := sim.SelectSteps(2)
+	require.NoError(t, err)
+	assert.False(t, single.Meta.Flags.hasFlag(Stepped))
+	vout, err := GetTrace[float64](sim, "V(out)")
+	require.NoError(t, err)
+	got, err := GetTrace[float64](single, "V(out)")
+	require.NoError(t, err)
+	assert.Equal(t, vout.GetSignal(2), got.GetSignal())
+
+	_, err = sim.SelectSteps(4)
+	assert.Error(t, err)
+}
+
+func TestSelectStepsComplex(t *testing.T) {
+	sim, err := Parse("testdata/simulations/ac/low-pass/low-pass-filter.raw")
+	require.NoError(t, err)
+
+	out, err := sim.SelectSteps(0)
+	require.NoError(t, err)
+	assert.Equal(t, sim.complexData["V(n002)"], out.complexData["V(n002)"])
+}
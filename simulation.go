@@ -2,6 +2,7 @@ package ltspice
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -28,6 +29,21 @@ func (s SimType) String() string {
 }
 
 func simTypeFromString(str string) (SimType, error) {
+	// ngspice writes the plot names in varying case and with a "Curves" suffix for noise
+	switch strings.ToLower(str) {
+	case "operating point":
+		return OperatingPoint, nil
+	case "dc transfer characteristic":
+		return DCtransfer, nil
+	case "ac analysis":
+		return ACAnalysis, nil
+	case "transient analysis":
+		return TransientAnalysis, nil
+	case "noise spectral density curves":
+		return NoiseSpectralDensity, nil
+	case "transfer function":
+		return TransferFunction, nil
+	}
 	switch str {
 	case "Operating Point":
 		return OperatingPoint, nil
@@ -50,8 +66,10 @@ func simTypeFromString(str string) (SimType, error) {
 
 // MetaData defines the metadata of a simulation.
 type MetaData struct {
-	Title        string
-	Date         time.Time
+	Title string
+	Date  time.Time
+	// PlotName is the plot name as written in the raw file, e.g. "Noise Spectral Density Curves".
+	PlotName     string
 	SimType      SimType
 	Flags        Flags
 	NoVariables  int
@@ -59,6 +77,8 @@ type MetaData struct {
 	NoIterations int
 	Offset       float64
 	Command      string
+	// Output is the output node of a noise analysis.
+	Output       string
 	Variables    []Variable
 	BinaryOffset int
 }
@@ -71,6 +91,7 @@ type SimData struct {
 	xAxisLabel  string
 	steps       *steps
 	stepPoints  int
+	op          *SimData
 }
 
 // GetType retrieves the type of the simulation
@@ -93,6 +114,8 @@ func (sim *SimData) GetVariables() []Variable {
 // The Data field is a flat slice that contains all signals.
 type Trace[T float64 | complex128] struct {
 	s    *steps
+	sim  *SimData  // source of the x-axis of traces returned by GetTrace
+	x    []float64 // flat x-axis of all steps of derived traces without a source
 	Name string
 	Data []T
 }
@@ -140,7 +163,7 @@ func GetTrace[T float64 | complex128](sim *SimData, name string) (*Trace[T], err
 		}
 	}
 
-	return &Trace[T]{Name: name, Data: traceData, s: sim.steps}, nil
+	return &Trace[T]{Name: name, Data: traceData, s: sim.steps, sim: sim}, nil
 }
 
 // GetSignal returns the data contained in the trace.
@@ -171,29 +194,42 @@ func GetTrace[T float64 | complex128](sim *SimData, name string) (*Trace[T], err
 //	    plot(timeWave, currentWave, fmt.Sprintf("Step %d", step))
 //	}
 func (t *Trace[T]) GetSignal(step ...int) []T {
-	if t.s.count <= 1 {
+	return stepSlice(t.s, t.Data, step...)
+}
+
+// GetXAxis returns the x-axis of the trace, following the same step rules as GetSignal.
+func (t *Trace[T]) GetXAxis(step ...int) []float64 {
+	if t.sim != nil {
+		return t.sim.GetXAxis(step...)
+	}
+	return stepSlice(t.s, t.x, step...)
+}
+
+// stepSlice returns the part of the flat data of all steps that belongs to a step.
+func stepSlice[E any](s *steps, data []E, step ...int) []E {
+	if s.count <= 1 {
 		// If the simulation is not stepped, return all data
-		return t.Data
+		return data
 	}
 
 	if len(step) == 0 {
 		// If no step index is provided, return the data for the first step
-		return t.Data[0:t.s.offsets[1]]
+		return data[0:s.offsets[1]]
 	}
 
 	stepIndex := step[0]
-	if stepIndex < 0 || stepIndex >= t.s.count {
+	if stepIndex < 0 || stepIndex >= s.count {
 		// If the step index is out of range, return an empty slice
-		return []T{}
+		return []E{}
 	}
 
-	start := t.s.offsets[stepIndex]
-	end := len(t.Data)
-	if stepIndex+1 < len(t.s.offsets) {
-		end = t.s.offsets[stepIndex+1]
+	start := s.offsets[stepIndex]
+	end := len(data)
+	if stepIndex+1 < len(s.offsets) {
+		end = s.offsets[stepIndex+1]
 	}
 
-	return t.Data[start:end]
+	return data[start:end]
 }
 
 // GetXAxis returns the x-axis data for the simulation.
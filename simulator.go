@@ -0,0 +1,215 @@
+package ltspice
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Simulator runs a simulation job and returns the parsed results.
+type Simulator interface {
+	Simulate(ctx context.Context, job Job) (*Result, error)
+}
+
+// Job describes a single simulation run.
+type Job struct {
+	// Name is the base name of the netlist file, e.g. "amp" for amp.net. Defaults to "circuit".
+	Name string
+	// Netlist is the content of the SPICE netlist.
+	Netlist []byte
+	// Files holds additional files written next to the netlist, e.g. included models, keyed by
+	// their path relative to the working directory.
+	Files map[string][]byte
+}
+
+// Result holds the outcome of a simulation job.
+type Result struct {
+	Job Job
+	// Sim is the parsed raw file.
+	Sim *SimData
+	// Log is the parsed log file, nil if the simulator did not write one.
+	Log *LogFile
+	// Output holds everything the simulator wrote to stdout and stderr.
+	Output []byte
+	// Dir is the working directory of the run. It is only set if the directory was kept.
+	Dir string
+	// Duration is the wall time of the run.
+	Duration time.Duration
+}
+
+// LTSpice runs LTSpice in batch mode. The zero value is not usable, Executable must be set.
+//
+// Example usage:
+//
+//	sim := &ltspice.LTSpice{
+//	    Executable: "wine",
+//	    Args:       []string{`C:\Program Files\ADI\LTspice\LTspice.exe`},
+//	    Timeout:    time.Minute,
+//	}
+//	res, err := sim.Simulate(ctx, ltspice.Job{Name: "amp", Netlist: netlist})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	vout, err := ltspice.GetTrace[float64](res.Sim, "V(out)")
+type LTSpice struct {
+	// Executable is the LTSpice binary, or a wrapper such as wine.
+	Executable string
+	// Args are passed before the batch mode flags, e.g. the path of LTSpice.exe when running through wine.
+	Args []string
+	// ExtraArgs are passed after the batch mode flags, e.g. "-alt" or "-fastaccess".
+	ExtraArgs []string
+	// Env holds additional environment variables in the form "KEY=value".
+	Env []string
+	// WorkDir is the directory in which temporary working directories are created. Defaults to os.TempDir.
+	WorkDir string
+	// KeepWorkDir keeps the working directory after the run instead of removing it.
+	KeepWorkDir bool
+	// Timeout limits the run time of a single simulation. Zero means no limit besides the context.
+	Timeout time.Duration
+}
+
+// Simulate writes the netlist into a new working directory, runs LTSpice in batch mode and parses the
+// resulting raw and log files.
+//
+// If the timeout or the context expires the simulator process is killed and the context error is
+// returned. If LTSpice fails or writes no raw file, ErrSimulationFailed is returned together with the
+// errors reported in the log. Convergence failures additionally wrap ErrConvergence.
+func (l *LTSpice) Simulate(ctx context.Context, job Job) (*Result, error) {
+	if l.Executable == "" {
+		return nil, fmt.Errorf("%w: no executable configured", ErrSimulationFailed)
+	}
+	if job.Name == "" {
+		job.Name = "circuit"
+	}
+
+	dir, err := os.MkdirTemp(l.WorkDir, "ltspice-")
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{Job: job}
+	if l.KeepWorkDir {
+		res.Dir = dir
+	} else {
+		defer os.RemoveAll(dir)
+	}
+
+	netlist := job.Name + ".net"
+	if err := writeJobFiles(dir, netlist, job); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	output, err := l.run(ctx, dir, append([]string{"-b"}, append(l.ExtraArgs, netlist)...))
+	res.Output = output
+	res.Duration = time.Since(start)
+	if ctx.Err() != nil || errors.Is(err, context.DeadlineExceeded) {
+		return res, err
+	}
+
+	res.Log, _ = ParseLog(filepath.Join(dir, job.Name+".log"))
+	if err != nil {
+		return res, simulationError(res.Log, err)
+	}
+	res.Sim, err = Parse(filepath.Join(dir, job.Name+".raw"))
+	if err != nil {
+		return res, simulationError(res.Log, err)
+	}
+	return res, nil
+}
+
+// Netlist converts a schematic (.asc) into a netlist by running LTSpice with -netlist.
+// The schematic is copied into a temporary directory, so the symbols it uses must be
+// part of the LTSpice library.
+func (l *LTSpice) Netlist(ctx context.Context, schematic string) ([]byte, error) {
+	asc, err := os.ReadFile(schematic)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(l.WorkDir, "ltspice-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Base(schematic)
+	if err := os.WriteFile(filepath.Join(dir, name), asc, 0o644); err != nil {
+		return nil, err
+	}
+	if _, err := l.run(ctx, dir, []string{"-netlist", name}); err != nil {
+		return nil, err
+	}
+	netlist, err := os.ReadFile(filepath.Join(dir, strings.TrimSuffix(name, filepath.Ext(name))+".net"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: no netlist written: %v", ErrSimulationFailed, err)
+	}
+	return netlist, nil
+}
+
+// run executes the simulator in dir and returns its combined output.
+func (l *LTSpice) run(ctx context.Context, dir string, args []string) ([]byte, error) {
+	if l.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, l.Executable, append(append([]string{}, l.Args...), args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), l.Env...)
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return output.Bytes(), fmt.Errorf("%w: %s %s", ctx.Err(), l.Executable, strings.Join(args, " "))
+	}
+	if err != nil {
+		return output.Bytes(), fmt.Errorf("%w: %v", ErrSimulationFailed, err)
+	}
+	return output.Bytes(), nil
+}
+
+func writeJobFiles(dir, netlist string, job Job) error {
+	if err := os.WriteFile(filepath.Join(dir, netlist), job.Netlist, 0o644); err != nil {
+		return err
+	}
+	for name, content := range job.Files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if !strings.HasPrefix(path, filepath.Clean(dir)+string(filepath.Separator)) {
+			return fmt.Errorf("%w: file %s is outside the working directory", ErrSimulationFailed, name)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// simulationError adds the errors reported in the log to err and makes sure it wraps ErrSimulationFailed.
+// If the log reports convergence problems the error also wraps ErrConvergence.
+func simulationError(l *LogFile, err error) error {
+	if errors.Is(err, os.ErrNotExist) {
+		err = errors.New("no raw file written")
+	}
+	if !errors.Is(err, ErrSimulationFailed) {
+		err = fmt.Errorf("%w: %w", ErrSimulationFailed, err)
+	}
+	if l == nil || len(l.Errors) == 0 {
+		return err
+	}
+	msg := strings.Join(l.Errors, "; ")
+	if isConvergenceMessage(msg) {
+		return fmt.Errorf("%w: %w: %s", err, ErrConvergence, msg)
+	}
+	return fmt.Errorf("%w: %s", err, msg)
+}
@@ -0,0 +1,166 @@
+package ltspice
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFakeLTSpice is not a real test. It acts as a fake LTSpice executable when the test binary is
+// started by fakeSimulator. The fake reads "* key: value" comments from the netlist: "fixture" copies
+// the raw and log files of a testdata fixture into the working directory, "log" writes the value as
+// log file, "sleep" delays the run and "exit" terminates with the given status.
+func TestFakeLTSpice(t *testing.T) {
+	if os.Getenv("LTSPICE_FAKE") != "1" {
+		t.Skip("only runs as a fake LTSpice executable")
+	}
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	os.Exit(fakeLTSpice(args[1:]))
+}
+
+func fakeLTSpice(args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintf(os.Stderr, "unexpected arguments %v\n", args)
+		return 2
+	}
+	name := strings.TrimSuffix(args[1], filepath.Ext(args[1]))
+	if args[0] == "-netlist" {
+		content := fmt.Sprintf("* %s\n.end\n", args[1])
+		if err := os.WriteFile(name+".net", []byte(content), 0o644); err != nil {
+			return 1
+		}
+		return 0
+	}
+
+	netlist, err := os.ReadFile(args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Println("fake LTSpice running", args[1])
+	s := bufio.NewScanner(bytes.NewReader(netlist))
+	for s.Scan() {
+		key, value, ok := strings.Cut(strings.TrimPrefix(s.Text(), "* "), ": ")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "sleep":
+			d, _ := time.ParseDuration(value)
+			time.Sleep(d)
+		case "log":
+			if os.WriteFile(name+".log", []byte(value+"\n"), 0o644) != nil {
+				return 1
+			}
+		case "exit":
+			code, _ := strconv.Atoi(value)
+			return code
+		case "fixture":
+			fixture := filepath.Join(os.Getenv("LTSPICE_TESTDATA"), value)
+			for _, ext := range []string{".raw", ".log", ".op.raw"} {
+				b, err := os.ReadFile(fixture + ext)
+				if errors.Is(err, os.ErrNotExist) {
+					continue
+				}
+				if err != nil || os.WriteFile(name+ext, b, 0o644) != nil {
+					return 1
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func fakeSimulator(t *testing.T) *LTSpice {
+	testdata, err := filepath.Abs("testdata/simulations")
+	require.NoError(t, err)
+	return &LTSpice{
+		Executable: os.Args[0],
+		Args:       []string{"-test.run=^TestFakeLTSpice$", "--"},
+		Env:        []string{"LTSPICE_FAKE=1", "LTSPICE_TESTDATA=" + testdata},
+		WorkDir:    t.TempDir(),
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	sim := fakeSimulator(t)
+	var _ Simulator = sim
+
+	res, err := sim.Simulate(context.Background(), Job{
+		Name:    "amp",
+		Netlist: []byte("* fixture: trans/LM741/LM741\n.tran 1m\n.end\n"),
+		Files:   map[string][]byte{"models/lm741.sub": []byte("* model\n")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, TransientAnalysis, res.Sim.GetType())
+	require.NotNil(t, res.Log)
+	assert.Contains(t, res.Log.Circuit, "LM741")
+	assert.Contains(t, string(res.Output), "fake LTSpice running amp.net")
+	assert.Empty(t, res.Dir)
+
+	entries, err := os.ReadDir(sim.WorkDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "working directory should be removed")
+}
+
+func TestSimulateKeepWorkDir(t *testing.T) {
+	sim := fakeSimulator(t)
+	sim.KeepWorkDir = true
+
+	res, err := sim.Simulate(context.Background(), Job{Netlist: []byte("* fixture: op/op\n"), Files: map[string][]byte{"inc/a.lib": nil}})
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(res.Dir, "circuit.net"))
+	assert.FileExists(t, filepath.Join(res.Dir, "circuit.raw"))
+	assert.FileExists(t, filepath.Join(res.Dir, "inc", "a.lib"))
+}
+
+func TestSimulateErrors(t *testing.T) {
+	sim := fakeSimulator(t)
+
+	_, err := sim.Simulate(context.Background(), Job{Netlist: []byte("* exit: 3\n")})
+	assert.ErrorIs(t, err, ErrSimulationFailed)
+
+	_, err = sim.Simulate(context.Background(), Job{Netlist: []byte("* nothing\n")})
+	assert.ErrorIs(t, err, ErrSimulationFailed)
+	assert.ErrorContains(t, err, "no raw file written")
+
+	res, err := sim.Simulate(context.Background(), Job{Netlist: []byte("* log: Analysis: Time step too small; time = 1e-3\n")})
+	assert.ErrorIs(t, err, ErrSimulationFailed)
+	assert.ErrorIs(t, err, ErrConvergence)
+	require.NotNil(t, res.Log)
+	assert.Len(t, res.Log.Errors, 1)
+
+	_, err = sim.Simulate(context.Background(), Job{Files: map[string][]byte{"../escape": nil}})
+	assert.ErrorIs(t, err, ErrSimulationFailed)
+
+	sim.Timeout = 50 * time.Millisecond
+	_, err = sim.Simulate(context.Background(), Job{Netlist: []byte("* sleep: 10s\n")})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = (&LTSpice{}).Simulate(context.Background(), Job{})
+	assert.ErrorIs(t, err, ErrSimulationFailed)
+}
+
+func TestNetlist(t *testing.T) {
+	sim := fakeSimulator(t)
+	asc := filepath.Join(t.TempDir(), "amp.asc")
+	require.NoError(t, os.WriteFile(asc, []byte("Version 4\n"), 0o644))
+
+	netlist, err := sim.Netlist(context.Background(), asc)
+	require.NoError(t, err)
+	assert.Equal(t, "* amp.asc\n.end\n", string(netlist))
+}
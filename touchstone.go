@@ -0,0 +1,608 @@
+package ltspice
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"math/cmplx"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ParameterType is the type of network parameters, S (scattering), Y (admittance) or Z (impedance).
+type ParameterType byte
+
+const (
+	SParameters ParameterType = 'S'
+	YParameters ParameterType = 'Y'
+	ZParameters ParameterType = 'Z'
+)
+
+func (p ParameterType) String() string {
+	return string(p)
+}
+
+// TouchstoneFormat is the number format used for the network data in a touchstone file.
+type TouchstoneFormat string
+
+const (
+	MagnitudeAngle TouchstoneFormat = "MA" // magnitude and angle in degrees
+	DecibelAngle   TouchstoneFormat = "DB" // magnitude in dB and angle in degrees
+	RealImaginary  TouchstoneFormat = "RI" // real and imaginary parts
+)
+
+var touchstoneFrequencyUnits = map[string]float64{
+	"HZ":  1,
+	"KHZ": 1e3,
+	"MHZ": 1e6,
+	"GHZ": 1e9,
+}
+
+// Port defines a port of a network for the computation of network parameters from an AC analysis.
+type Port struct {
+	// Voltage is the name of the trace holding the port voltage, e.g. "V(p1)".
+	Voltage string
+	// Current is the name of the trace holding the current flowing into the port, e.g. "I(R1)".
+	Current string
+	// ReverseCurrent negates the current trace. The current of an excitation source
+	// (I(V1) for a source between the port and ground) flows out of the port.
+	ReverseCurrent bool
+	// Z0 is the reference impedance of the port in Ohm, defaults to 50.
+	Z0 float64
+}
+
+// Network holds the parameters of an n-port network over frequency.
+type Network struct {
+	Type      ParameterType
+	Ports     int
+	Frequency []float64
+	// Z0 holds the (real) reference impedance of every port.
+	Z0 []float64
+	// Data holds one row-major Ports x Ports matrix per frequency, Data[f][i*Ports+j] is the parameter ij.
+	Data [][]complex128
+}
+
+// At returns the parameter ij (zero based port indices) for every frequency.
+func (n *Network) At(i, j int) []complex128 {
+	out := make([]complex128, len(n.Data))
+	for f := range n.Data {
+		out[f] = n.Data[f][i*n.Ports+j]
+	}
+	return out
+}
+
+// NewNetwork computes the network parameters of type typ from an AC analysis.
+//
+// An n-port network requires n excitations. For a single port an unstepped simulation is
+// sufficient, for n ports the simulation must be stepped n times with only port k excited
+// in step k (e.g. `.step param port 1 2` selecting the active AC source). The port voltages
+// and currents of step k form the k-th column of the voltage and current matrices, Z = V * I^-1.
+//
+// Example usage:
+//
+//	ports := []ltspice.Port{
+//	    {Voltage: "V(p1)", Current: "I(V1)", ReverseCurrent: true},
+//	    {Voltage: "V(p2)", Current: "I(V2)", ReverseCurrent: true},
+//	}
+//	s, err := ltspice.NewNetwork(simData, ports, ltspice.SParameters)
+func NewNetwork(sim *SimData, ports []Port, typ ParameterType) (*Network, error) {
+	if !sim.Meta.Flags.hasFlag(Complex) {
+		return nil, fmt.Errorf("%w: network parameters require an AC analysis, got %s", ErrInvalidSimulationType, sim.GetType())
+	}
+	n := len(ports)
+	if n == 0 {
+		return nil, fmt.Errorf("network: at least one port is required")
+	}
+	if n > 1 && sim.GetSteps() != n {
+		return nil, fmt.Errorf("network: %d ports require a simulation with %d steps, got %d", n, n, sim.GetSteps())
+	}
+
+	freq := sim.GetXAxis(0)
+	// voltages[i][k] and currents[i][k] hold the signal of port i in step k
+	voltages := make([][][]complex128, n)
+	currents := make([][][]complex128, n)
+	z0 := make([]float64, n)
+	for i, p := range ports {
+		z0[i] = p.Z0
+		if z0[i] == 0 {
+			z0[i] = 50
+		}
+		v, err := GetTrace[complex128](sim, p.Voltage)
+		if err != nil {
+			return nil, fmt.Errorf("network: port %d voltage %s: %w", i+1, p.Voltage, err)
+		}
+		c, err := GetTrace[complex128](sim, p.Current)
+		if err != nil {
+			return nil, fmt.Errorf("network: port %d current %s: %w", i+1, p.Current, err)
+		}
+		voltages[i] = make([][]complex128, n)
+		currents[i] = make([][]complex128, n)
+		for k := 0; k < n; k++ {
+			voltages[i][k] = v.GetSignal(k)
+			currents[i][k] = c.GetSignal(k)
+			if len(voltages[i][k]) != len(freq) || len(currents[i][k]) != len(freq) {
+				return nil, fmt.Errorf("network: step %d has a different number of points than step 0", k)
+			}
+		}
+	}
+
+	net := &Network{Type: ZParameters, Ports: n, Frequency: freq, Z0: z0, Data: make([][]complex128, len(freq))}
+	for f := range freq {
+		vm := make([]complex128, n*n)
+		im := make([]complex128, n*n)
+		for i := 0; i < n; i++ {
+			for k := 0; k < n; k++ {
+				vm[i*n+k] = voltages[i][k][f]
+				im[i*n+k] = currents[i][k][f]
+				if ports[i].ReverseCurrent {
+					im[i*n+k] = -im[i*n+k]
+				}
+			}
+		}
+		inv, err := cmatInverse(im, n)
+		if err != nil {
+			return nil, fmt.Errorf("network: current matrix at %g Hz: %w", freq[f], err)
+		}
+		net.Data[f] = cmatMul(vm, inv, n)
+	}
+	return net.Convert(typ)
+}
+
+// Convert returns a copy of the network with parameters of type typ.
+func (n *Network) Convert(typ ParameterType) (*Network, error) {
+	out := &Network{Type: typ, Ports: n.Ports, Frequency: n.Frequency, Z0: n.Z0, Data: make([][]complex128, len(n.Data))}
+	for f, m := range n.Data {
+		z, err := n.toZ(m)
+		if err != nil {
+			return nil, fmt.Errorf("network: %g Hz: %w", n.Frequency[f], err)
+		}
+		if out.Data[f], err = n.fromZ(z, typ); err != nil {
+			return nil, fmt.Errorf("network: %g Hz: %w", n.Frequency[f], err)
+		}
+	}
+	return out, nil
+}
+
+func (n *Network) toZ(m []complex128) ([]complex128, error) {
+	p := n.Ports
+	switch n.Type {
+	case ZParameters:
+		return append([]complex128(nil), m...), nil
+	case YParameters:
+		return cmatInverse(m, p)
+	case SParameters:
+		// Z = sqrt(R) (1 - S)^-1 (1 + S) sqrt(R)
+		a := cmatIdentity(p)
+		b := cmatIdentity(p)
+		for i := range m {
+			a[i] -= m[i]
+			b[i] += m[i]
+		}
+		inv, err := cmatInverse(a, p)
+		if err != nil {
+			return nil, err
+		}
+		z := cmatMul(inv, b, p)
+		for i := 0; i < p; i++ {
+			for j := 0; j < p; j++ {
+				z[i*p+j] *= complex(math.Sqrt(n.Z0[i]*n.Z0[j]), 0)
+			}
+		}
+		return z, nil
+	}
+	return nil, fmt.Errorf("unknown parameter type %q", n.Type)
+}
+
+func (n *Network) fromZ(z []complex128, typ ParameterType) ([]complex128, error) {
+	p := n.Ports
+	switch typ {
+	case ZParameters:
+		return z, nil
+	case YParameters:
+		return cmatInverse(z, p)
+	case SParameters:
+		// S = (z - 1)(z + 1)^-1 with the normalized impedance z = R^-1/2 Z R^-1/2
+		a := make([]complex128, p*p)
+		b := make([]complex128, p*p)
+		for i := 0; i < p; i++ {
+			for j := 0; j < p; j++ {
+				zn := z[i*p+j] / complex(math.Sqrt(n.Z0[i]*n.Z0[j]), 0)
+				a[i*p+j], b[i*p+j] = zn, zn
+			}
+			a[i*p+i] -= 1
+			b[i*p+i] += 1
+		}
+		inv, err := cmatInverse(b, p)
+		if err != nil {
+			return nil, err
+		}
+		return cmatMul(a, inv, p), nil
+	}
+	return nil, fmt.Errorf("unknown parameter type %q", typ)
+}
+
+// TouchstoneOptions configures WriteTouchstone.
+type TouchstoneOptions struct {
+	// Version is the touchstone version, 1 or 2. Defaults to 1.
+	Version int
+	// Format is the number format of the network data. Defaults to MagnitudeAngle.
+	Format TouchstoneFormat
+	// FrequencyUnit is one of Hz, kHz, MHz or GHz. Defaults to Hz.
+	FrequencyUnit string
+	// Comments are written at the top of the file.
+	Comments []string
+}
+
+// WriteTouchstone writes the network as a touchstone (.sNp) file.
+//
+// Version 1 files only support a single reference impedance, Z and Y parameters are normalized
+// to it as required by the specification. Version 2 files support a reference impedance per port
+// and store Z and Y parameters in Ohm and Siemens.
+func WriteTouchstone(w io.Writer, n *Network, opts TouchstoneOptions) error {
+	if opts.Version == 0 {
+		opts.Version = 1
+	}
+	if opts.Format == "" {
+		opts.Format = MagnitudeAngle
+	}
+	if opts.FrequencyUnit == "" {
+		opts.FrequencyUnit = "Hz"
+	}
+	unit, ok := touchstoneFrequencyUnits[strings.ToUpper(opts.FrequencyUnit)]
+	if !ok {
+		return fmt.Errorf("touchstone: unknown frequency unit %q", opts.FrequencyUnit)
+	}
+	if opts.Version != 1 && opts.Version != 2 {
+		return fmt.Errorf("touchstone: unsupported version %d", opts.Version)
+	}
+	r := n.Z0[0]
+	for _, z0 := range n.Z0 {
+		if opts.Version == 1 && z0 != r {
+			return fmt.Errorf("touchstone: version 1 files do not support per port reference impedances")
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, c := range opts.Comments {
+		fmt.Fprintf(bw, "! %s\n", c)
+	}
+	if opts.Version == 2 {
+		fmt.Fprintf(bw, "[Version] 2.0\n")
+	}
+	fmt.Fprintf(bw, "# %s %s %s R %s\n", opts.FrequencyUnit, n.Type, opts.Format, formatTouchstoneNumber(r))
+	if opts.Version == 2 {
+		fmt.Fprintf(bw, "[Number of Ports] %d\n", n.Ports)
+		if n.Ports == 2 {
+			fmt.Fprintf(bw, "[Two-Port Data Order] 12_21\n")
+		}
+		fmt.Fprintf(bw, "[Number of Frequencies] %d\n", len(n.Frequency))
+		fmt.Fprintf(bw, "[Reference]")
+		for _, z0 := range n.Z0 {
+			fmt.Fprintf(bw, " %s", formatTouchstoneNumber(z0))
+		}
+		fmt.Fprintf(bw, "\n[Network Data]\n")
+	}
+
+	normalize := complex(1, 0)
+	if opts.Version == 1 {
+		switch n.Type {
+		case ZParameters:
+			normalize = complex(1/r, 0)
+		case YParameters:
+			normalize = complex(r, 0)
+		}
+	}
+	for f, m := range n.Data {
+		fmt.Fprintf(bw, "%s", formatTouchstoneNumber(n.Frequency[f]/unit))
+		for idx, pos := range touchstoneOrder(n.Ports, opts.Version) {
+			if col := idx % n.Ports; idx > 0 && n.Ports > 2 && col%4 == 0 {
+				bw.WriteString("\n")
+			}
+			a, b := formatTouchstoneValue(m[pos]*normalize, opts.Format)
+			fmt.Fprintf(bw, " %s %s", a, b)
+		}
+		bw.WriteString("\n")
+	}
+	if opts.Version == 2 {
+		fmt.Fprintf(bw, "[End]\n")
+	}
+	return bw.Flush()
+}
+
+// touchstoneOrder returns the row-major matrix indices in the order they appear in a file.
+// Version 1 two-port files use the order 11 21 12 22.
+func touchstoneOrder(ports, version int) []int {
+	order := make([]int, ports*ports)
+	for i := range order {
+		order[i] = i
+	}
+	if ports == 2 && version == 1 {
+		order[1], order[2] = 2, 1
+	}
+	return order
+}
+
+func formatTouchstoneNumber(v float64) string {
+	return strconv.FormatFloat(v, 'g', 12, 64)
+}
+
+func formatTouchstoneValue(c complex128, format TouchstoneFormat) (string, string) {
+	switch format {
+	case DecibelAngle:
+		return formatTouchstoneNumber(20 * math.Log10(cmplx.Abs(c))), formatTouchstoneNumber(cmplx.Phase(c) * 180 / math.Pi)
+	case RealImaginary:
+		return formatTouchstoneNumber(real(c)), formatTouchstoneNumber(imag(c))
+	default:
+		return formatTouchstoneNumber(cmplx.Abs(c)), formatTouchstoneNumber(cmplx.Phase(c) * 180 / math.Pi)
+	}
+}
+
+func parseTouchstoneValue(a, b float64, format TouchstoneFormat) complex128 {
+	switch format {
+	case DecibelAngle:
+		return cmplx.Rect(math.Pow(10, a/20), b*math.Pi/180)
+	case RealImaginary:
+		return complex(a, b)
+	default:
+		return cmplx.Rect(a, b*math.Pi/180)
+	}
+}
+
+var touchstoneExtension = regexp.MustCompile(`(?i)^\.s(\d+)p$`)
+
+// ReadTouchstoneFile reads a touchstone file. For version 1 files the number of ports is
+// taken from the file extension (.s1p, .s2p ...).
+func ReadTouchstoneFile(fileName string) (*Network, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ports := 0
+	if m := touchstoneExtension.FindStringSubmatch(filepath.Ext(fileName)); m != nil {
+		ports, _ = strconv.Atoi(m[1])
+	}
+	return ReadTouchstone(f, ports)
+}
+
+// ReadTouchstone reads a version 1 or 2 touchstone file. ports is required for version 1 files
+// and ignored if the file specifies [Number of Ports]. Noise data is ignored.
+// Z and Y parameters of version 1 files are de-normalized using the reference impedance.
+func ReadTouchstone(r io.Reader, ports int) (*Network, error) {
+	net := &Network{Type: SParameters}
+	format := MagnitudeAngle
+	unit := 1e9
+	r0 := 50.0
+	version := 1
+	order := ""
+	matrixFormat := "FULL"
+	var reference []float64
+	var values []float64
+	inData := version == 1
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '!'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "#") {
+			fields := strings.Fields(strings.ToUpper(line[1:]))
+			for i := 0; i < len(fields); i++ {
+				f := fields[i]
+				switch {
+				case touchstoneFrequencyUnits[f] != 0:
+					unit = touchstoneFrequencyUnits[f]
+				case f == "S" || f == "Y" || f == "Z":
+					net.Type = ParameterType(f[0])
+				case f == "MA" || f == "DB" || f == "RI":
+					format = TouchstoneFormat(f)
+				case f == "R" && i+1 < len(fields):
+					v, err := strconv.ParseFloat(fields[i+1], 64)
+					if err != nil {
+						return nil, fmt.Errorf("%w: line %d: invalid reference impedance %q", ErrInvalidTouchstone, lineNo, fields[i+1])
+					}
+					r0 = v
+					i++
+				default:
+					return nil, fmt.Errorf("%w: line %d: unsupported option %q", ErrInvalidTouchstone, lineNo, f)
+				}
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: line %d: malformed keyword", ErrInvalidTouchstone, lineNo)
+			}
+			keyword := strings.ToUpper(line[1:end])
+			arg := strings.TrimSpace(line[end+1:])
+			switch keyword {
+			case "VERSION":
+				version = 2
+				inData = false
+			case "NUMBER OF PORTS":
+				n, err := strconv.Atoi(arg)
+				if err != nil {
+					return nil, fmt.Errorf("%w: line %d: invalid number of ports", ErrInvalidTouchstone, lineNo)
+				}
+				ports = n
+			case "TWO-PORT DATA ORDER":
+				order = arg
+			case "MATRIX FORMAT":
+				matrixFormat = strings.ToUpper(arg)
+			case "REFERENCE":
+				for _, f := range strings.Fields(arg) {
+					v, err := strconv.ParseFloat(f, 64)
+					if err != nil {
+						return nil, fmt.Errorf("%w: line %d: invalid reference impedance %q", ErrInvalidTouchstone, lineNo, f)
+					}
+					reference = append(reference, v)
+				}
+			case "NETWORK DATA":
+				inData = true
+			case "NOISE DATA", "END":
+				inData = false
+			}
+			continue
+		}
+
+		if !inData {
+			// continuation of the [Reference] keyword
+			if len(reference) > 0 && len(reference) < ports {
+				for _, f := range strings.Fields(line) {
+					v, err := strconv.ParseFloat(f, 64)
+					if err != nil {
+						return nil, fmt.Errorf("%w: line %d: invalid reference impedance %q", ErrInvalidTouchstone, lineNo, f)
+					}
+					reference = append(reference, v)
+				}
+			}
+			continue
+		}
+		for _, f := range strings.Fields(line) {
+			v, err := strconv.ParseFloat(f, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: invalid number %q", ErrInvalidTouchstone, lineNo, f)
+			}
+			values = append(values, v)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if ports <= 0 {
+		return nil, fmt.Errorf("%w: unknown number of ports", ErrInvalidTouchstone)
+	}
+
+	net.Ports = ports
+	net.Z0 = make([]float64, ports)
+	for i := range net.Z0 {
+		net.Z0[i] = r0
+		if i < len(reference) {
+			net.Z0[i] = reference[i]
+		}
+	}
+
+	// matrix positions in the order they appear in the file
+	var positions []int
+	for i := 0; i < ports; i++ {
+		for j := 0; j < ports; j++ {
+			if matrixFormat == "LOWER" && j > i || matrixFormat == "UPPER" && j < i {
+				continue
+			}
+			positions = append(positions, i*ports+j)
+		}
+	}
+	if ports == 2 && (version == 1 || order == "21_12") {
+		positions[1], positions[2] = positions[2], positions[1]
+	}
+
+	normalize := complex(1, 0)
+	if version == 1 {
+		switch net.Type {
+		case ZParameters:
+			normalize = complex(r0, 0)
+		case YParameters:
+			normalize = complex(1/r0, 0)
+		}
+	}
+
+	pointSize := 1 + 2*len(positions)
+	for len(values) >= pointSize {
+		freq := values[0] * unit
+		if len(net.Frequency) > 0 && freq <= net.Frequency[len(net.Frequency)-1] {
+			// version 1 noise parameters follow the network data with restarting frequencies
+			break
+		}
+		m := make([]complex128, ports*ports)
+		for k, pos := range positions {
+			m[pos] = parseTouchstoneValue(values[1+2*k], values[2+2*k], format) * normalize
+			if matrixFormat != "FULL" {
+				i, j := pos/ports, pos%ports
+				m[j*ports+i] = m[pos]
+			}
+		}
+		net.Frequency = append(net.Frequency, freq)
+		net.Data = append(net.Data, m)
+		values = values[pointSize:]
+	}
+	if len(values) > 0 && len(net.Data) == 0 {
+		return nil, fmt.Errorf("%w: incomplete network data", ErrInvalidTouchstone)
+	}
+	return net, nil
+}
+
+func cmatIdentity(n int) []complex128 {
+	m := make([]complex128, n*n)
+	for i := 0; i < n; i++ {
+		m[i*n+i] = 1
+	}
+	return m
+}
+
+func cmatMul(a, b []complex128, n int) []complex128 {
+	out := make([]complex128, n*n)
+	for i := 0; i < n; i++ {
+		for k := 0; k < n; k++ {
+			aik := a[i*n+k]
+			for j := 0; j < n; j++ {
+				out[i*n+j] += aik * b[k*n+j]
+			}
+		}
+	}
+	return out
+}
+
+// cmatInverse inverts the row-major n x n matrix m using Gauss-Jordan elimination with partial pivoting.
+func cmatInverse(m []complex128, n int) ([]complex128, error) {
+	a := append([]complex128(nil), m...)
+	inv := cmatIdentity(n)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if cmplx.Abs(a[r*n+col]) > cmplx.Abs(a[pivot*n+col]) {
+				pivot = r
+			}
+		}
+		if cmplx.Abs(a[pivot*n+col]) == 0 {
+			return nil, ErrSingularMatrix
+		}
+		if pivot != col {
+			for j := 0; j < n; j++ {
+				a[col*n+j], a[pivot*n+j] = a[pivot*n+j], a[col*n+j]
+				inv[col*n+j], inv[pivot*n+j] = inv[pivot*n+j], inv[col*n+j]
+			}
+		}
+		p := a[col*n+col]
+		for j := 0; j < n; j++ {
+			a[col*n+j] /= p
+			inv[col*n+j] /= p
+		}
+		for r := 0; r < n; r++ {
+			if r == col {
+				continue
+			}
+			factor := a[r*n+col]
+			if factor == 0 {
+				continue
+			}
+			for j := 0; j < n; j++ {
+				a[r*n+j] -= factor * a[col*n+j]
+				inv[r*n+j] -= factor * inv[col*n+j]
+			}
+		}
+	}
+	return inv, nil
+}
@@ -0,0 +1,170 @@
+package ltspice
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/cmplx"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNetworkOnePort(t *testing.T) {
+	// low-pass-filter: V1 drives R1 = 1k in series with C1 = 1u
+	sim, err := Parse("testdata/simulations/ac/low-pass/low-pass-filter.raw")
+	require.NoError(t, err)
+
+	z, err := NewNetwork(sim, []Port{{Voltage: "V(n001)", Current: "I(V1)", ReverseCurrent: true}}, ZParameters)
+	require.NoError(t, err)
+	require.Len(t, z.Data, len(sim.GetXAxis()))
+	for f, freq := range z.Frequency {
+		want := complex(1e3, 0) + 1/complex(0, 2*math.Pi*freq*1e-6)
+		assert.InDelta(t, 0, cmplx.Abs(z.Data[f][0]-want)/cmplx.Abs(want), 1e-5, "Z11 at %g Hz", freq)
+	}
+
+	s, err := NewNetwork(sim, []Port{{Voltage: "V(n001)", Current: "I(V1)", ReverseCurrent: true, Z0: 75}}, SParameters)
+	require.NoError(t, err)
+	for f := range s.Data {
+		zf := z.Data[f][0]
+		want := (zf - 75) / (zf + 75)
+		assert.InDelta(t, 0, cmplx.Abs(s.Data[f][0]-want), 1e-6)
+	}
+
+	_, err = NewNetwork(sim, []Port{{Voltage: "V(n001)", Current: "I(V1)"}, {Voltage: "V(n002)", Current: "I(C1)"}}, SParameters)
+	assert.Error(t, err, "two ports require two steps")
+}
+
+func twoPortNetwork() *Network {
+	return &Network{
+		Type:      SParameters,
+		Ports:     2,
+		Frequency: []float64{1e6, 2e6, 3e6},
+		Z0:        []float64{50, 50},
+		Data: [][]complex128{
+			{complex(0.1, 0.2), complex(0.8, -0.1), complex(0.7, 0.05), complex(-0.2, 0.3)},
+			{complex(0.15, 0.1), complex(0.75, -0.2), complex(0.65, 0.1), complex(-0.1, 0.25)},
+			{complex(0.2, 0.05), complex(0.7, -0.3), complex(0.6, 0.15), complex(0.05, 0.2)},
+		},
+	}
+}
+
+func assertNetworksEqual(t *testing.T, want, got *Network, delta float64) {
+	t.Helper()
+	require.Equal(t, want.Ports, got.Ports)
+	require.Equal(t, want.Type, got.Type)
+	assert.InDeltaSlice(t, want.Frequency, got.Frequency, delta)
+	assert.InDeltaSlice(t, want.Z0, got.Z0, delta)
+	require.Len(t, got.Data, len(want.Data))
+	for f := range want.Data {
+		for i := range want.Data[f] {
+			assert.InDelta(t, 0, cmplx.Abs(want.Data[f][i]-got.Data[f][i]), delta, "freq %d param %d", f, i)
+		}
+	}
+}
+
+func TestNetworkConvert(t *testing.T) {
+	s := twoPortNetwork()
+	s.Z0 = []float64{50, 75}
+	z, err := s.Convert(ZParameters)
+	require.NoError(t, err)
+	y, err := z.Convert(YParameters)
+	require.NoError(t, err)
+	back, err := y.Convert(SParameters)
+	require.NoError(t, err)
+	assertNetworksEqual(t, s, back, 1e-12)
+
+	// Y must be the inverse of Z
+	for f := range z.Data {
+		id := cmatMul(z.Data[f], y.Data[f], 2)
+		assert.InDelta(t, 0, cmplx.Abs(id[0]-1)+cmplx.Abs(id[1])+cmplx.Abs(id[2])+cmplx.Abs(id[3]-1), 1e-12)
+	}
+}
+
+func TestTouchstoneRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  ParameterType
+		opts TouchstoneOptions
+	}{
+		{name: "v1 S MA", typ: SParameters, opts: TouchstoneOptions{}},
+		{name: "v1 S DB MHz", typ: SParameters, opts: TouchstoneOptions{Format: DecibelAngle, FrequencyUnit: "MHz"}},
+		{name: "v1 Z RI", typ: ZParameters, opts: TouchstoneOptions{Format: RealImaginary}},
+		{name: "v2 Y RI", typ: YParameters, opts: TouchstoneOptions{Version: 2, Format: RealImaginary, FrequencyUnit: "GHz"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net, err := twoPortNetwork().Convert(tt.typ)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			require.NoError(t, WriteTouchstone(&buf, net, tt.opts))
+			got, err := ReadTouchstone(&buf, 2)
+			require.NoError(t, err)
+			assertNetworksEqual(t, net, got, 1e-9)
+		})
+	}
+}
+
+func TestWriteTouchstoneV1TwoPortOrder(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteTouchstone(&buf, twoPortNetwork(), TouchstoneOptions{Format: RealImaginary}))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, "# Hz S RI R 50", lines[0])
+	// the order of two-port data in version 1 files is 11 21 12 22
+	assert.Equal(t, "1000000 0.1 0.2 0.7 0.05 0.8 -0.1 -0.2 0.3", lines[1])
+}
+
+func TestReadTouchstone(t *testing.T) {
+	input := `! measured with a VNA
+# MHz S MA R 50
+! freq  S11
+1       0.5 -90
+2       0.4 180
+`
+	net, err := ReadTouchstone(strings.NewReader(input), 1)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1e6, 2e6}, net.Frequency)
+	assert.InDelta(t, 0, cmplx.Abs(net.Data[0][0]-complex(0, -0.5)), 1e-12)
+	assert.InDelta(t, 0, cmplx.Abs(net.Data[1][0]-complex(-0.4, 0)), 1e-12)
+
+	_, err = ReadTouchstone(strings.NewReader(input), 0)
+	assert.True(t, errors.Is(err, ErrInvalidTouchstone))
+
+	input = `[Version] 2.0
+# GHz Z RI R 50
+[Number of Ports] 3
+[Number of Frequencies] 1
+[Reference] 50 75
+90
+[Matrix Format] Lower
+[Network Data]
+1.0 1 0
+    2 0 3 0
+    4 0 5 0 6 0
+[End]
+`
+	net, err = ReadTouchstone(strings.NewReader(input), 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, net.Ports)
+	assert.Equal(t, ZParameters, net.Type)
+	assert.Equal(t, []float64{50, 75, 90}, net.Z0)
+	assert.Equal(t, []float64{1e9}, net.Frequency)
+	assert.Equal(t, []complex128{1, 2, 4, 2, 3, 5, 4, 5, 6}, net.Data[0])
+}
+
+func TestReadTouchstoneV1NoiseData(t *testing.T) {
+	input := `# GHz S RI R 50
+1 0.1 0 0.9 0 0.9 0 0.1 0
+2 0.2 0 0.8 0 0.8 0 0.2 0
+! noise parameters
+1 1.5 0.5 30 0.2
+2 1.8 0.4 45 0.25
+`
+	net, err := ReadTouchstone(strings.NewReader(input), 2)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1e9, 2e9}, net.Frequency)
+	assert.Equal(t, complex(0.8, 0), net.Data[1][1])
+}
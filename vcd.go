@@ -0,0 +1,228 @@
+package ltspice
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var vcdTimeUnits = map[string]float64{
+	"s":  1,
+	"ms": 1e-3,
+	"us": 1e-6,
+	"ns": 1e-9,
+	"ps": 1e-12,
+	"fs": 1e-15,
+}
+
+// VCDOptions configures WriteVCD.
+type VCDOptions struct {
+	// Timescale is the VCD timescale, e.g. "1ps", "10ns" or "100 us". Defaults to "1ps".
+	Timescale string
+	// Step selects the step of a stepped simulation.
+	Step int
+	// Module is the name of the VCD scope holding the variables. Defaults to "ltspice".
+	Module string
+	// Reals lists the traces written as real variables. If both Reals and Wires are empty
+	// all traces are written as real variables.
+	Reals []string
+	// Wires lists the traces converted into logic wires.
+	Wires []VCDWire
+}
+
+// VCDWire describes the conversion of an analog trace into a logic wire.
+//
+// The wire goes high when the signal rises above VIH + Hysteresis/2 and low when it
+// falls below VIL - Hysteresis/2. In between the wire keeps its previous level, or is
+// undefined ('x') if Undefined is set. The initial level is 'x' until a threshold is crossed.
+type VCDWire struct {
+	Trace      string
+	Name       string // name of the wire in the VCD file, defaults to Trace
+	VIL        float64
+	VIH        float64
+	Hysteresis float64
+	Undefined  bool
+}
+
+func (w VCDWire) level(v float64, prev byte) byte {
+	switch {
+	case v >= w.VIH+w.Hysteresis/2:
+		return '1'
+	case v <= w.VIL-w.Hysteresis/2:
+		return '0'
+	case w.Undefined:
+		return 'x'
+	default:
+		return prev
+	}
+}
+
+// WriteVCD writes transient traces as a Value Change Dump which can be viewed in GTKWave
+// and other waveform viewers. The time axis is taken from GetXAxis and rounded to the
+// configured timescale, points falling on the same tick collapse into the last one.
+//
+// Example usage:
+//
+//	err := ltspice.WriteVCD(f, simData, ltspice.VCDOptions{
+//	    Timescale: "1ns",
+//	    Reals:     []string{"V(out)"},
+//	    Wires:     []ltspice.VCDWire{{Trace: "V(clk)", Name: "clk", VIL: 0.8, VIH: 2.0}},
+//	})
+func WriteVCD(w io.Writer, sim *SimData, opts VCDOptions) error {
+	if sim.GetType() != TransientAnalysis {
+		return fmt.Errorf("%w: VCD export requires a transient analysis, got %s", ErrInvalidSimulationType, sim.GetType())
+	}
+	if opts.Step < 0 || opts.Step >= sim.GetSteps() {
+		return fmt.Errorf("step %d out of range, the simulation has %d steps", opts.Step, sim.GetSteps())
+	}
+	if opts.Timescale == "" {
+		opts.Timescale = "1ps"
+	}
+	if opts.Module == "" {
+		opts.Module = "ltspice"
+	}
+	tick, err := parseVCDTimescale(opts.Timescale)
+	if err != nil {
+		return err
+	}
+	if len(opts.Reals) == 0 && len(opts.Wires) == 0 {
+		for _, v := range sim.Meta.Variables[1:] {
+			opts.Reals = append(opts.Reals, v.Name)
+		}
+	}
+
+	xAxis := sim.GetXAxis(opts.Step)
+	reals := make([][]float64, len(opts.Reals))
+	for i, name := range opts.Reals {
+		if reals[i], err = vcdSignal(sim, name, opts.Step, len(xAxis)); err != nil {
+			return err
+		}
+	}
+	wires := make([][]float64, len(opts.Wires))
+	for i, wire := range opts.Wires {
+		if wires[i], err = vcdSignal(sim, wire.Trace, opts.Step, len(xAxis)); err != nil {
+			return err
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "$date\n\t%s\n$end\n", sim.Meta.Date.Format(time.ANSIC))
+	fmt.Fprintf(bw, "$version\n\tltspice-go\n$end\n")
+	if sim.Meta.Title != "" {
+		fmt.Fprintf(bw, "$comment\n\t%s\n$end\n", sim.Meta.Title)
+	}
+	fmt.Fprintf(bw, "$timescale %s $end\n", strings.ReplaceAll(opts.Timescale, " ", ""))
+	fmt.Fprintf(bw, "$scope module %s $end\n", vcdName(opts.Module))
+	for i, name := range opts.Reals {
+		fmt.Fprintf(bw, "$var real 64 %s %s $end\n", vcdIdentifier(i), vcdName(name))
+	}
+	for i, wire := range opts.Wires {
+		name := wire.Name
+		if name == "" {
+			name = wire.Trace
+		}
+		fmt.Fprintf(bw, "$var wire 1 %s %s $end\n", vcdIdentifier(len(reals)+i), vcdName(name))
+	}
+	fmt.Fprintf(bw, "$upscope $end\n$enddefinitions $end\n")
+
+	realValues := make([]float64, len(reals))
+	lastReals := make([]float64, len(reals))
+	wireValues := make([]byte, len(wires))
+	lastWires := make([]byte, len(wires))
+	for i := range wireValues {
+		wireValues[i] = 'x'
+	}
+
+	flush := func(t int64, initial bool) {
+		fmt.Fprintf(bw, "#%d\n", t)
+		if initial {
+			fmt.Fprintf(bw, "$dumpvars\n")
+		}
+		for i, v := range realValues {
+			if initial || v != lastReals[i] {
+				fmt.Fprintf(bw, "r%s %s\n", strconv.FormatFloat(v, 'g', -1, 64), vcdIdentifier(i))
+				lastReals[i] = v
+			}
+		}
+		for i, v := range wireValues {
+			if initial || v != lastWires[i] {
+				fmt.Fprintf(bw, "%c%s\n", v, vcdIdentifier(len(reals)+i))
+				lastWires[i] = v
+			}
+		}
+		if initial {
+			fmt.Fprintf(bw, "$end\n")
+		}
+	}
+
+	current, first := int64(math.MinInt64), true
+	for p, t := range xAxis {
+		ts := int64(math.Round(t / tick))
+		if ts != current && p > 0 {
+			flush(current, first)
+			first = false
+		}
+		current = ts
+		for i := range reals {
+			realValues[i] = reals[i][p]
+		}
+		for i, wire := range opts.Wires {
+			wireValues[i] = wire.level(wires[i][p], wireValues[i])
+		}
+	}
+	if len(xAxis) > 0 {
+		flush(current, first)
+	}
+	return bw.Flush()
+}
+
+func vcdSignal(sim *SimData, name string, step, n int) ([]float64, error) {
+	trace, err := GetTrace[float64](sim, name)
+	if err != nil {
+		return nil, fmt.Errorf("vcd: %s: %w", name, err)
+	}
+	signal := trace.GetSignal(step)
+	if len(signal) != n {
+		return nil, fmt.Errorf("vcd: %s: expected %d points but found %d", name, n, len(signal))
+	}
+	return signal, nil
+}
+
+// parseVCDTimescale parses a VCD timescale such as "10ns" and returns its value in seconds.
+func parseVCDTimescale(s string) (float64, error) {
+	s = strings.ReplaceAll(s, " ", "")
+	for _, magnitude := range []string{"100", "10", "1"} {
+		if !strings.HasPrefix(s, magnitude) {
+			continue
+		}
+		unit, ok := vcdTimeUnits[strings.TrimPrefix(s, magnitude)]
+		if !ok {
+			break
+		}
+		m, _ := strconv.ParseFloat(magnitude, 64)
+		return m * unit, nil
+	}
+	return 0, fmt.Errorf("%w: %q, expected 1, 10 or 100 followed by s, ms, us, ns, ps or fs", ErrInvalidTimescale, s)
+}
+
+// vcdIdentifier returns the short identifier code of the i-th variable using printable ASCII characters.
+func vcdIdentifier(i int) string {
+	const first, n = '!', '~' - '!' + 1
+	var id []byte
+	for {
+		id = append(id, byte(first+i%n))
+		i = i/n - 1
+		if i < 0 {
+			return string(id)
+		}
+	}
+}
+
+// vcdName removes whitespace which is not allowed in VCD reference names.
+func vcdName(name string) string {
+	return strings.Join(strings.Fields(name), "_")
+}
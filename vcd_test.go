@@ -0,0 +1,115 @@
+package ltspice
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVCDTimescale(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		wantErr bool
+	}{
+		{input: "1ps", want: 1e-12},
+		{input: "10ns", want: 10e-9},
+		{input: "100 us", want: 100e-6},
+		{input: "1s", want: 1},
+		{input: "5ns", wantErr: true},
+		{input: "1000ns", wantErr: true},
+		{input: "1xs", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseVCDTimescale(tt.input)
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrInvalidTimescale))
+				return
+			}
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.want, got, tt.want*1e-12)
+		})
+	}
+}
+
+func TestVCDIdentifier(t *testing.T) {
+	assert.Equal(t, "!", vcdIdentifier(0))
+	assert.Equal(t, "~", vcdIdentifier(93))
+	assert.Equal(t, "!!", vcdIdentifier(94))
+	seen := map[string]bool{}
+	for i := 0; i < 10000; i++ {
+		id := vcdIdentifier(i)
+		assert.False(t, seen[id], "duplicate identifier %q", id)
+		seen[id] = true
+	}
+}
+
+func TestVCDWireLevel(t *testing.T) {
+	w := VCDWire{VIL: 0.8, VIH: 2.0, Hysteresis: 0.2}
+	assert.Equal(t, byte('1'), w.level(2.1, 'x'))
+	assert.Equal(t, byte('1'), w.level(1.5, '1'))
+	assert.Equal(t, byte('0'), w.level(1.5, '0'))
+	assert.Equal(t, byte('1'), w.level(2.15, '0'), "hysteresis should not prevent a transition above VIH + H/2")
+	assert.Equal(t, byte('0'), w.level(2.0, '0'), "hysteresis should delay the rising transition")
+	assert.Equal(t, byte('0'), w.level(0.7, '1'))
+
+	w.Undefined = true
+	assert.Equal(t, byte('x'), w.level(1.5, '1'))
+}
+
+func TestWriteVCD(t *testing.T) {
+	sim, err := Parse("testdata/simulations/trans/stepped/tran-stepped.raw")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = WriteVCD(&buf, sim, VCDOptions{
+		Timescale: "1ns",
+		Step:      1,
+		Reals:     []string{"V(n002)"},
+		Wires:     []VCDWire{{Trace: "V(n001)", Name: "in", VIL: 0.5, VIH: 0.5}},
+	})
+	require.NoError(t, err)
+
+	out := buf.String()
+	assert.Contains(t, out, "$timescale 1ns $end")
+	assert.Contains(t, out, "$var real 64 ! V(n002) $end")
+	assert.Contains(t, out, "$var wire 1 \" in $end")
+	assert.Contains(t, out, "$enddefinitions $end")
+	assert.Contains(t, out, "#0\n$dumpvars\n")
+
+	var last int64 = -1
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(line, "#") {
+			continue
+		}
+		ts, err := strconv.ParseInt(line[1:], 10, 64)
+		require.NoError(t, err)
+		assert.Greater(t, ts, last, "timestamps must be strictly increasing")
+		last = ts
+	}
+	xAxis := sim.GetXAxis(1)
+	assert.Equal(t, int64(xAxis[len(xAxis)-1]*1e9+0.5), last)
+}
+
+func TestWriteVCDRequiresTransient(t *testing.T) {
+	sim, err := Parse("testdata/simulations/ac/low-pass/low-pass-filter.raw")
+	require.NoError(t, err)
+	err = WriteVCD(&bytes.Buffer{}, sim, VCDOptions{})
+	assert.True(t, errors.Is(err, ErrInvalidSimulationType))
+}
+
+func TestWriteVCDStepOutOfRange(t *testing.T) {
+	sim, err := Parse("testdata/simulations/trans/stepped/tran-stepped.raw")
+	require.NoError(t, err)
+	for _, step := range []int{-1, sim.GetSteps()} {
+		var buf bytes.Buffer
+		assert.Error(t, WriteVCD(&buf, sim, VCDOptions{Step: step}))
+		assert.Zero(t, buf.Len())
+	}
+}
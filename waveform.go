@@ -0,0 +1,295 @@
+package ltspice
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// WaveformOptions configures the reference levels and thresholds of waveform measurements.
+type WaveformOptions struct {
+	// Low and High are the reference levels of 0% and 100%. If both are zero, they are taken from the waveform,
+	// see StepResponse and Pulse.
+	Low, High float64
+	// LowThreshold and HighThreshold are the thresholds of rise and fall times as fractions of the swing
+	// between Low and High, defaults to 0.1 and 0.9.
+	LowThreshold, HighThreshold float64
+	// MidThreshold is the threshold of delays, periods and pulse widths, defaults to 0.5.
+	MidThreshold float64
+	// SettlingBand is the tolerance band around the final value as a fraction of the swing, defaults to 0.02.
+	SettlingBand float64
+	// Start and Stop limit the measurement to a window of the x-axis. Zero values select the first and last
+	// point of each step.
+	Start, Stop float64
+}
+
+func (o *WaveformOptions) setDefaults() {
+	if o.LowThreshold == 0 {
+		o.LowThreshold = 0.1
+	}
+	if o.HighThreshold == 0 {
+		o.HighThreshold = 0.9
+	}
+	if o.MidThreshold == 0 {
+		o.MidThreshold = 0.5
+	}
+	if o.SettlingBand == 0 {
+		o.SettlingBand = 0.02
+	}
+}
+
+// StepResponse holds the step response metrics of a trace. Metrics which cannot be determined, e.g. the
+// fall time of a rising step, are NaN.
+type StepResponse struct {
+	// Initial and Final are the values at the start and end of the window.
+	Initial, Final float64
+	// Low and High are the reference levels the metrics are relative to.
+	Low, High float64
+	// RiseTime and FallTime are the durations of the first rising and falling transition between the
+	// low and high threshold.
+	RiseTime, FallTime float64
+	// Overshoot and Undershoot are the peak excursions above High and below Low as fractions of the swing.
+	Overshoot, Undershoot float64
+	// SettlingTime is the time from the start of the window until the trace stays within the settling band
+	// around the final value.
+	SettlingTime float64
+	// SlewRate is the average slope between the thresholds of the transition from the initial to the final
+	// value, in units per second. It is negative for falling steps.
+	SlewRate float64
+}
+
+// StepResponse measures the step response of a trace of a transient analysis. For stepped simulations a step
+// index selects the step, the first step is used if none is given.
+//
+// Unless Low and High are set, the reference levels are the initial and final value of the window, so
+// the window should start at the step of the stimulus and end after the trace settled.
+//
+// Example usage:
+//
+//	r, err := simData.StepResponse("V(out)", ltspice.WaveformOptions{Start: 1e-3, SettlingBand: 0.01})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("rise time %g s, overshoot %.1f%%\n", r.RiseTime, 100*r.Overshoot)
+func (sim *SimData) StepResponse(name string, opts WaveformOptions, step ...int) (*StepResponse, error) {
+	x, y, err := sim.waveform(name, opts, step...)
+	if err != nil {
+		return nil, err
+	}
+	opts.setDefaults()
+
+	r := &StepResponse{Initial: y[0], Final: y[len(y)-1], Low: opts.Low, High: opts.High}
+	if r.Low == 0 && r.High == 0 {
+		r.Low, r.High = math.Min(r.Initial, r.Final), math.Max(r.Initial, r.Final)
+	}
+	swing := r.High - r.Low
+	if swing <= 0 {
+		return nil, fmt.Errorf("step response of %s: no swing between the reference levels", name)
+	}
+	low, high := r.Low+opts.LowThreshold*swing, r.Low+opts.HighThreshold*swing
+	r.RiseTime = transitionTime(x, y, low, high, true)
+	r.FallTime = transitionTime(x, y, low, high, false)
+
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range y {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	r.Overshoot = math.Max(0, (hi-r.High)/swing)
+	r.Undershoot = math.Max(0, (r.Low-lo)/swing)
+
+	band := opts.SettlingBand * swing
+	r.SettlingTime = 0
+	for i := len(y) - 1; i >= 0; i-- {
+		if math.Abs(y[i]-r.Final) > band {
+			if i == len(y)-1 {
+				r.SettlingTime = math.NaN()
+			} else {
+				r.SettlingTime = x[i+1] - x[0]
+			}
+			break
+		}
+	}
+
+	r.SlewRate = math.NaN()
+	switch {
+	case r.Final > r.Initial && !math.IsNaN(r.RiseTime):
+		r.SlewRate = (high - low) / r.RiseTime
+	case r.Final < r.Initial && !math.IsNaN(r.FallTime):
+		r.SlewRate = -(high - low) / r.FallTime
+	}
+	return r, nil
+}
+
+// Pulse holds the timing of a periodic or pulsed trace, measured at the mid threshold. Metrics which cannot
+// be determined are NaN.
+type Pulse struct {
+	// Low and High are the reference levels the threshold is relative to.
+	Low, High float64
+	// Period is the mean time between rising crossings and Frequency its inverse.
+	Period, Frequency float64
+	// DutyCycle is the mean fraction of a period the trace is above the threshold.
+	DutyCycle float64
+	// PulseWidth is the duration of the first complete positive pulse.
+	PulseWidth float64
+}
+
+// Pulse measures the period, frequency, duty cycle and pulse width of a trace. For stepped simulations a step
+// index selects the step, the first step is used if none is given. Unless Low and High are set, the reference
+// levels are the minimum and maximum of the window.
+func (sim *SimData) Pulse(name string, opts WaveformOptions, step ...int) (*Pulse, error) {
+	x, y, err := sim.waveform(name, opts, step...)
+	if err != nil {
+		return nil, err
+	}
+	opts.setDefaults()
+
+	p := &Pulse{Low: opts.Low, High: opts.High, Period: math.NaN(), Frequency: math.NaN(), DutyCycle: math.NaN(), PulseWidth: math.NaN()}
+	if p.Low == 0 && p.High == 0 {
+		p.Low, p.High = math.Inf(1), math.Inf(-1)
+		for _, v := range y {
+			p.Low, p.High = math.Min(p.Low, v), math.Max(p.High, v)
+		}
+	}
+	if p.High <= p.Low {
+		return nil, fmt.Errorf("pulse of %s: no swing between the reference levels", name)
+	}
+	crossings := levelCrossings(x, y, p.Low+opts.MidThreshold*(p.High-p.Low))
+
+	var rising []int
+	for i, c := range crossings {
+		if c.rising {
+			rising = append(rising, i)
+		}
+	}
+	for _, i := range rising {
+		if i+1 < len(crossings) {
+			p.PulseWidth = crossings[i+1].x - crossings[i].x
+			break
+		}
+	}
+	if len(rising) < 2 {
+		return p, nil
+	}
+	first, last := rising[0], rising[len(rising)-1]
+	p.Period = (crossings[last].x - crossings[first].x) / float64(len(rising)-1)
+	p.Frequency = 1 / p.Period
+	var high float64
+	for i := first; i < last; i++ {
+		if crossings[i].rising {
+			high += crossings[i+1].x - crossings[i].x
+		}
+	}
+	p.DutyCycle = high / (crossings[last].x - crossings[first].x)
+	return p, nil
+}
+
+// PropagationDelay returns the time from the first crossing of the mid threshold of the trace from to the
+// next crossing of the mid threshold of the trace to. Unless Low and High are set, the reference levels of
+// each trace are its minimum and maximum in the window.
+func (sim *SimData) PropagationDelay(from, to string, opts WaveformOptions, step ...int) (float64, error) {
+	opts.setDefaults()
+	crossing := func(name string, after float64) (float64, error) {
+		x, y, err := sim.waveform(name, opts, step...)
+		if err != nil {
+			return 0, err
+		}
+		low, high := opts.Low, opts.High
+		if low == 0 && high == 0 {
+			low, high = math.Inf(1), math.Inf(-1)
+			for _, v := range y {
+				low, high = math.Min(low, v), math.Max(high, v)
+			}
+		}
+		for _, c := range levelCrossings(x, y, low+opts.MidThreshold*(high-low)) {
+			if c.x >= after {
+				return c.x, nil
+			}
+		}
+		return 0, fmt.Errorf("propagation delay: %s does not cross its threshold", name)
+	}
+	t0, err := crossing(from, math.Inf(-1))
+	if err != nil {
+		return 0, err
+	}
+	t1, err := crossing(to, t0)
+	if err != nil {
+		return 0, err
+	}
+	return t1 - t0, nil
+}
+
+// waveform returns the x-axis and the values of a real trace within the window of opts.
+func (sim *SimData) waveform(name string, opts WaveformOptions, step ...int) ([]float64, []float64, error) {
+	if sim.Meta.Flags.hasFlag(Complex) {
+		return nil, nil, fmt.Errorf("%w: waveform measurements require real data, got %s", ErrInvalidSimulationType, sim.GetType())
+	}
+	trace, err := GetTrace[float64](sim, name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", err, name)
+	}
+	x, y := sim.GetXAxis(step...), trace.GetSignal(step...)
+	if len(x) < 2 {
+		return nil, nil, errors.New("waveform: the step holds less than two points")
+	}
+	lo, hi, err := xWindow(x, opts.Start, opts.Stop)
+	if err != nil {
+		return nil, nil, fmt.Errorf("waveform: %w", err)
+	}
+	start := sort.SearchFloat64s(x, lo)
+	end := sort.Search(len(x), func(i int) bool { return x[i] > hi })
+	x, y = x[start:end], y[start:end]
+	if len(x) < 2 {
+		return nil, nil, errors.New("waveform: the window holds less than two points")
+	}
+	return x, y, nil
+}
+
+type crossing struct {
+	x      float64
+	rising bool
+}
+
+// levelCrossings returns the interpolated points at which y crosses level.
+func levelCrossings(x, y []float64, level float64) []crossing {
+	var out []crossing
+	for i := 1; i < len(y); i++ {
+		a, b := y[i-1]-level, y[i]-level
+		if (a < 0 && b >= 0) || (a >= 0 && b < 0) {
+			t := a / (a - b)
+			out = append(out, crossing{x: x[i-1] + t*(x[i]-x[i-1]), rising: b >= 0})
+		}
+	}
+	return out
+}
+
+// transitionTime returns the duration of the first rising (or falling) transition from the low to the high
+// threshold (or back). Ringing around the start threshold is skipped by using its last crossing before the end
+// threshold is reached.
+func transitionTime(x, y []float64, low, high float64, rising bool) float64 {
+	from, to := low, high
+	if !rising {
+		from, to = high, low
+	}
+	var end float64
+	found := false
+	for _, c := range levelCrossings(x, y, to) {
+		if c.rising == rising {
+			end, found = c.x, true
+			break
+		}
+	}
+	if !found {
+		return math.NaN()
+	}
+	start := math.NaN()
+	for _, c := range levelCrossings(x, y, from) {
+		if c.x > end {
+			break
+		}
+		if c.rising == rising {
+			start = c.x
+		}
+	}
+	return end - start
+}
@@ -0,0 +1,168 @@
+package ltspice
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waveformSim returns a transient analysis of the given functions sampled at 20001 points from 0 to stop.
+func waveformSim(t *testing.T, stop float64, fns map[string]func(float64) float64) *SimData {
+	names := []string{"time"}
+	data := [][]float64{nil}
+	for name := range fns {
+		names = append(names, name)
+		data = append(data, nil)
+	}
+	for i := 0; i <= 20000; i++ {
+		tm := stop * float64(i) / 20000
+		data[0] = append(data[0], tm)
+		for j, name := range names[1:] {
+			data[j+1] = append(data[j+1], fns[name](tm))
+		}
+	}
+	sim, err := newTableSim(TransientAnalysis, "waveform", names, data, nil)
+	require.NoError(t, err)
+	return sim
+}
+
+func TestStepResponseFirstOrder(t *testing.T) {
+	const tau = 1e-3
+	sim := waveformSim(t, 10*tau, map[string]func(float64) float64{
+		"V(rise)": func(tm float64) float64 { return 5 * (1 - math.Exp(-tm/tau)) },
+		"V(fall)": func(tm float64) float64 { return 5 * math.Exp(-tm/tau) },
+	})
+
+	r, err := sim.StepResponse("V(rise)", WaveformOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, r.Initial)
+	assert.InDelta(t, 5, r.Final, 1e-3)
+	assert.InDelta(t, tau*math.Log(9), r.RiseTime, 1e-5)
+	assert.True(t, math.IsNaN(r.FallTime))
+	assert.Equal(t, 0.0, r.Overshoot)
+	assert.Equal(t, 0.0, r.Undershoot)
+	assert.InDelta(t, tau*math.Log(50), r.SettlingTime, 1e-5)
+	assert.InDelta(t, 0.8*r.Final/(tau*math.Log(9)), r.SlewRate, 10)
+
+	r, err = sim.StepResponse("V(fall)", WaveformOptions{Low: 0, High: 5, SettlingBand: 0.01})
+	require.NoError(t, err)
+	assert.InDelta(t, tau*math.Log(9), r.FallTime, 1e-5)
+	assert.True(t, math.IsNaN(r.RiseTime))
+	assert.InDelta(t, tau*math.Log(100), r.SettlingTime, 1e-5)
+	assert.Less(t, r.SlewRate, 0.0)
+}
+
+func TestStepResponseOvershoot(t *testing.T) {
+	const zeta, f = 0.3, 1e3
+	w := 2 * math.Pi * f
+	wd := w * math.Sqrt(1-zeta*zeta)
+	sim := waveformSim(t, 10e-3, map[string]func(float64) float64{
+		"V(out)": func(tm float64) float64 {
+			if tm < 1e-3 {
+				return 0
+			}
+			tm -= 1e-3
+			return 1 - math.Exp(-zeta*w*tm)*(math.Cos(wd*tm)+zeta/math.Sqrt(1-zeta*zeta)*math.Sin(wd*tm))
+		},
+	})
+
+	r, err := sim.StepResponse("V(out)", WaveformOptions{})
+	require.NoError(t, err)
+	assert.InDelta(t, math.Exp(-math.Pi*zeta/math.Sqrt(1-zeta*zeta)), r.Overshoot, 1e-3)
+	assert.Equal(t, 0.0, r.Undershoot)
+	// ringing through the low threshold is ignored
+	assert.Greater(t, r.RiseTime, 0.0)
+	assert.Less(t, r.RiseTime, 0.5/f)
+
+	// the window starts at the step
+	windowed, err := sim.StepResponse("V(out)", WaveformOptions{Start: 1e-3})
+	require.NoError(t, err)
+	assert.InDelta(t, r.SettlingTime-1e-3, windowed.SettlingTime, 1e-6)
+	assert.Less(t, windowed.SettlingTime, 4/(zeta*w)*1.2)
+}
+
+func TestPulse(t *testing.T) {
+	square := func(delay float64) func(float64) float64 {
+		return func(tm float64) float64 {
+			phase := math.Mod(tm-delay+1e-3, 1e-3)
+			switch {
+			case phase < 10e-6:
+				return 3.3 * phase / 10e-6
+			case phase < 300e-6:
+				return 3.3
+			case phase < 310e-6:
+				return 3.3 * (310e-6 - phase) / 10e-6
+			default:
+				return 0
+			}
+		}
+	}
+	sim := waveformSim(t, 10.5e-3, map[string]func(float64) float64{
+		"V(in)":  square(0.2e-3),
+		"V(out)": square(0.25e-3),
+	})
+
+	p, err := sim.Pulse("V(in)", WaveformOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, p.Low)
+	assert.Equal(t, 3.3, p.High)
+	assert.InDelta(t, 1e-3, p.Period, 1e-8)
+	assert.InDelta(t, 1e3, p.Frequency, 1e-2)
+	assert.InDelta(t, 0.3, p.DutyCycle, 1e-4)
+	assert.InDelta(t, 300e-6, p.PulseWidth, 1e-8)
+
+	delay, err := sim.PropagationDelay("V(in)", "V(out)", WaveformOptions{})
+	require.NoError(t, err)
+	assert.InDelta(t, 50e-6, delay, 1e-8)
+
+	// a single pulse has no period
+	p, err = sim.Pulse("V(in)", WaveformOptions{Stop: 0.9e-3})
+	require.NoError(t, err)
+	assert.InDelta(t, 300e-6, p.PulseWidth, 1e-8)
+	assert.True(t, math.IsNaN(p.Period))
+	assert.True(t, math.IsNaN(p.DutyCycle))
+}
+
+func TestWaveformStepped(t *testing.T) {
+	sim, err := Parse("testdata/simulations/stepped/rc/rc.raw")
+	require.NoError(t, err)
+	require.Equal(t, 6, sim.GetSteps())
+	for step := 0; step < sim.GetSteps(); step++ {
+		p, err := sim.Pulse("V(n001)", WaveformOptions{}, step)
+		require.NoError(t, err)
+		assert.InDelta(t, 1e-3, p.Period, 1e-9)
+	}
+
+	// the time constant of the second step is twice the first
+	r0, err := sim.StepResponse("V(n002)", WaveformOptions{Low: 0, High: 1}, 0)
+	require.NoError(t, err)
+	r1, err := sim.StepResponse("V(n002)", WaveformOptions{Low: 0, High: 1}, 1)
+	require.NoError(t, err)
+	assert.InEpsilon(t, 2*r0.RiseTime, r1.RiseTime, 1e-2)
+}
+
+func TestWaveformErrors(t *testing.T) {
+	sim := waveformSim(t, 1, map[string]func(float64) float64{
+		"V(flat)": func(float64) float64 { return 1 },
+	})
+	_, err := sim.StepResponse("V(flat)", WaveformOptions{})
+	assert.Error(t, err)
+	_, err = sim.Pulse("V(flat)", WaveformOptions{})
+	assert.Error(t, err)
+	_, err = sim.PropagationDelay("V(flat)", "V(flat)", WaveformOptions{Low: 0, High: 4})
+	assert.Error(t, err)
+	_, err = sim.StepResponse("V(missing)", WaveformOptions{})
+	assert.ErrorIs(t, err, ErrTraceDoesNotExist)
+	_, err = sim.StepResponse("V(flat)", WaveformOptions{Start: 2, Stop: 3})
+	assert.Error(t, err)
+	// a stop before the start is not ignored
+	_, err = sim.StepResponse("V(flat)", WaveformOptions{Start: 0.5, Stop: 0.2})
+	assert.ErrorContains(t, err, "does not overlap")
+
+	ac, err := Parse("testdata/simulations/ac/low-pass/low-pass-filter.raw")
+	require.NoError(t, err)
+	_, err = ac.Pulse("V(n002)", WaveformOptions{})
+	assert.ErrorIs(t, err, ErrInvalidSimulationType)
+}
@@ -0,0 +1,506 @@
+package ltspice
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseXyce loads a Xyce output file. The format is chosen by the file extension: ".prn" for the
+// default .print table, ".csd" for Probe-style output and anything else for SPICE raw output
+// (format=raw or the -r command line option).
+//
+// Variable names are mapped to the conventions used by LTSpice, see XyceTraceName, so the same
+// names can be passed to GetTrace for results of both simulators.
+//
+// Example usage:
+//
+//	xyce, err := ltspice.ParseXyce("amp.cir.prn")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	vout, err := ltspice.GetTrace[float64](xyce, "V(out)")
+func ParseXyce(fileName string) (*SimData, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".prn":
+		return ParseXycePRN(file)
+	case ".csd":
+		return ParseXyceCSD(file)
+	}
+	sim, err := ParseFromReader(file)
+	if err != nil {
+		return nil, err
+	}
+	return renameXyce(sim), nil
+}
+
+// XyceTraceName maps a Xyce variable name to the LTSpice naming convention: node voltages such as
+// V(OUT) become V(out), device currents such as I(R1) or IC(Q1) become I(R1) and Ic(Q1), branch
+// currents such as V1#branch become I(V1) and the TIME and FREQ axes become time and frequency.
+//
+// Xyce upper-cases all names, so device names are mapped to an upper-case first letter followed by
+// lower-case letters, e.g. I(VIN) becomes I(Vin).
+func XyceTraceName(name string) string {
+	upper := strings.ToUpper(strings.TrimSpace(name))
+	switch upper {
+	case "TIME":
+		return "time"
+	case "FREQ", "FREQUENCY":
+		return "frequency"
+	}
+	if device, ok := strings.CutSuffix(upper, "#BRANCH"); ok {
+		return "I(" + xyceDeviceName(device) + ")"
+	}
+	open := strings.IndexByte(upper, '(')
+	if open <= 0 || !strings.HasSuffix(upper, ")") {
+		return strings.ToLower(name)
+	}
+	prefix, args := upper[:open], upper[open+1:len(upper)-1]
+	switch prefix[0] {
+	case 'V':
+		return "V(" + strings.ToLower(args) + ")"
+	case 'I':
+		return "I" + strings.ToLower(prefix[1:]) + "(" + xyceDeviceName(args) + ")"
+	}
+	return name
+}
+
+func xyceDeviceName(name string) string {
+	if name == "" {
+		return name
+	}
+	return name[:1] + strings.ToLower(name[1:])
+}
+
+// xyceTraceType returns the LTSpice variable type of a mapped trace name.
+func xyceTraceType(name string, simType SimType) string {
+	switch {
+	case name == "time", name == "frequency":
+		return name
+	case strings.HasPrefix(name, "I"):
+		return "device_current"
+	case strings.HasPrefix(name, "V("):
+		return "voltage"
+	}
+	if simType == DCtransfer {
+		return "voltage"
+	}
+	return "param"
+}
+
+// renameXyce maps the variable names of a raw file written by Xyce to the LTSpice conventions.
+func renameXyce(sim *SimData) *SimData {
+	vars := make([]Variable, len(sim.Meta.Variables))
+	for i, v := range sim.Meta.Variables {
+		name := XyceTraceName(v.Name)
+		if i > 0 && v.Typ == "voltage" && !strings.Contains(name, "(") {
+			// bare node names
+			name = "V(" + name + ")"
+		}
+		vars[i] = v
+		vars[i].Name = name
+	}
+	out := sim.withVariables(vars)
+	out.xAxisLabel = vars[0].Name
+	for i, v := range sim.Meta.Variables {
+		if sim.Meta.Flags.hasFlag(Complex) {
+			out.complexData[vars[i].Name] = sim.complexData[v.Name]
+		} else {
+			out.data[vars[i].Name] = sim.data[v.Name]
+		}
+	}
+	return out
+}
+
+// ParseXycePRN parses the default Xyce .print output: a whitespace (or comma) separated table with
+// a header line, an optional Index column and a "End of Xyce(TM) Simulation" footer.
+//
+// AC results printed as real and imaginary parts, e.g. Re(V(OUT)) and Im(V(OUT)) or VR(OUT) and
+// VI(OUT), are combined into complex traces. Other AC columns are stored as real values.
+func ParseXycePRN(r io.Reader) (*SimData, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var header []string
+	var rows [][]float64
+	comma := false
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "End of Xyce") {
+			continue
+		}
+		if header == nil {
+			comma = strings.Contains(line, ",")
+			header = splitPRN(line, comma)
+			continue
+		}
+		fields := splitPRN(line, comma)
+		if strings.EqualFold(fields[0], header[0]) {
+			// the header is repeated for every step
+			continue
+		}
+		if len(fields) != len(header) {
+			return nil, fmt.Errorf("%w: expected %d columns, got %d: %s", ErrParsingError, len(header), len(fields), line)
+		}
+		row := make([]float64, len(fields))
+		for i, f := range fields {
+			v, err := strconv.ParseFloat(f, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid value %q in column %s", ErrParsingError, f, header[i])
+			}
+			row[i] = v
+		}
+		rows = append(rows, row)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("%w: empty file", ErrParsingError)
+	}
+	if strings.EqualFold(header[0], "Index") {
+		header = header[1:]
+		for i := range rows {
+			rows[i] = rows[i][1:]
+		}
+	}
+	if len(header) == 0 || len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no data", ErrParsingError)
+	}
+
+	names := make([]string, len(header))
+	for i, h := range header {
+		names[i] = XyceTraceName(h)
+	}
+	simType := xyceSimType(names[0])
+	if simType != ACAnalysis {
+		columns := make([][]float64, len(names))
+		for _, row := range rows {
+			for i, v := range row {
+				columns[i] = append(columns[i], v)
+			}
+		}
+		return newTableSim(simType, "", names, columns, nil)
+	}
+
+	// combine real and imaginary columns of AC results
+	var traces []string
+	index := map[string]int{}
+	parts := make([][2]int, 0, len(header))
+	for i, h := range header {
+		name, part := xyceComplexPart(h)
+		j, ok := index[name]
+		if !ok {
+			j = len(traces)
+			index[name] = j
+			traces = append(traces, name)
+			parts = append(parts, [2]int{-1, -1})
+		}
+		parts[j][part] = i
+	}
+	columns := make([][]complex128, len(traces))
+	for _, row := range rows {
+		for j, p := range parts {
+			var c complex128
+			if p[0] >= 0 {
+				c += complex(row[p[0]], 0)
+			}
+			if p[1] >= 0 {
+				c += complex(0, row[p[1]])
+			}
+			columns[j] = append(columns[j], c)
+		}
+	}
+	return newTableSim(simType, "", traces, nil, columns)
+}
+
+func splitPRN(line string, comma bool) []string {
+	if !comma {
+		return strings.Fields(line)
+	}
+	fields := strings.Split(line, ",")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields
+}
+
+// xyceComplexPart maps AC column names to the trace name and the part they hold, 0 for the real
+// and 1 for the imaginary part.
+func xyceComplexPart(column string) (string, int) {
+	upper := strings.ToUpper(column)
+	switch {
+	case strings.HasPrefix(upper, "RE(") && strings.HasSuffix(upper, ")"):
+		return XyceTraceName(column[3 : len(column)-1]), 0
+	case strings.HasPrefix(upper, "IM(") && strings.HasSuffix(upper, ")"):
+		return XyceTraceName(column[3 : len(column)-1]), 1
+	case len(upper) > 3 && (upper[0] == 'V' || upper[0] == 'I') && upper[2] == '(':
+		// VR(OUT), VI(OUT), IR(R1), II(R1)
+		name := XyceTraceName(upper[:1] + upper[2:])
+		switch upper[1] {
+		case 'R':
+			return name, 0
+		case 'I':
+			return name, 1
+		}
+	}
+	return XyceTraceName(column), 0
+}
+
+func xyceSimType(xAxis string) SimType {
+	switch xAxis {
+	case "time":
+		return TransientAnalysis
+	case "frequency":
+		return ACAnalysis
+	}
+	return DCtransfer
+}
+
+// newTableSim builds a simulation from column data, the first column is the x-axis.
+// Exactly one of real and complexData must be set.
+func newTableSim(simType SimType, title string, names []string, real [][]float64, complexData [][]complex128) (*SimData, error) {
+	meta := &MetaData{
+		Title:       title,
+		SimType:     simType,
+		PlotName:    simType.String(),
+		Flags:       None | Forward | Double,
+		NoVariables: len(names),
+	}
+	sim := &SimData{Meta: meta, xAxisLabel: names[0]}
+	if complexData != nil {
+		meta.Flags = Complex | Forward | Double
+		sim.complexData = make(map[string][]complex128, len(names))
+	} else {
+		sim.data = make(map[string][]float64, len(names))
+	}
+	for i, name := range names {
+		if _, ok := sim.data[name]; ok {
+			return nil, fmt.Errorf("%w: duplicate variable %s", ErrParsingError, name)
+		}
+		if _, ok := sim.complexData[name]; ok {
+			return nil, fmt.Errorf("%w: duplicate variable %s", ErrParsingError, name)
+		}
+		meta.Variables = append(meta.Variables, Variable{order: i, Name: name, Typ: xyceTraceType(name, simType), size: realXAxisTraceByteSize})
+		if complexData != nil {
+			sim.complexData[name] = complexData[i]
+			meta.NoPoints = len(complexData[i])
+		} else {
+			sim.data[name] = real[i]
+			meta.NoPoints = len(real[i])
+		}
+	}
+	sim.stepPoints = meta.NoPoints
+
+	s, err := detectSteps(sim.flatXAxis())
+	if err != nil {
+		return nil, err
+	}
+	if s.count > 1 {
+		meta.Flags.setFlag(Stepped)
+	}
+	sim.steps = s
+	return sim, nil
+}
+
+// ParseXyceCSD parses Xyce output written with format=probe. The file consists of a header (#H),
+// the quoted variable names (#N) and one #C block per point holding the x value followed by
+// value:index pairs. Complex values are written as re/im:index. Stepped simulations repeat
+// the whole structure for every step.
+func ParseXyceCSD(r io.Reader) (*SimData, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var (
+		title, analysis, sweepVar string
+		isComplex                 bool
+		names                     []string
+		section                   string
+		x                         []float64
+		values                    [][]complex128
+		point                     []complex128
+		seen                      int
+	)
+	flush := func() error {
+		if point == nil {
+			return nil
+		}
+		if seen != len(names) {
+			return fmt.Errorf("%w: point %d has %d of %d values", ErrParsingError, len(x), seen, len(names))
+		}
+		for i, v := range point {
+			values[i] = append(values[i], v)
+		}
+		point = nil
+		return nil
+	}
+
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case line == "":
+			continue
+		case line == "#H":
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			section = "H"
+			continue
+		case line == "#N":
+			section = "N"
+			names = names[:0]
+			continue
+		case line == "#;":
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			section = ""
+			continue
+		case strings.HasPrefix(line, "#C"):
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("%w: invalid point %q", ErrParsingError, line)
+			}
+			v, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid point %q", ErrParsingError, line)
+			}
+			if values == nil {
+				values = make([][]complex128, len(names))
+			}
+			x = append(x, v)
+			point = make([]complex128, len(names))
+			seen = 0
+			section = "C"
+			continue
+		}
+
+		switch section {
+		case "H":
+			for key, value := range csdHeaderFields(line) {
+				switch key {
+				case "TITLE":
+					title = value
+				case "ANALYSIS":
+					analysis = value
+				case "SWEEPVAR":
+					sweepVar = value
+				case "COMPLEXVALUES":
+					isComplex = strings.EqualFold(value, "YES")
+				}
+			}
+		case "N":
+			for _, name := range csdNames(line) {
+				names = append(names, name)
+			}
+		case "C":
+			for _, field := range strings.Fields(line) {
+				value, idx, ok := strings.Cut(field, ":")
+				i, err := strconv.Atoi(idx)
+				if !ok || err != nil || i < 1 || i > len(names) {
+					return nil, fmt.Errorf("%w: invalid value %q", ErrParsingError, field)
+				}
+				re, im, _ := strings.Cut(value, "/")
+				a, err := strconv.ParseFloat(re, 64)
+				if err != nil {
+					return nil, fmt.Errorf("%w: invalid value %q", ErrParsingError, field)
+				}
+				b := 0.0
+				if im != "" {
+					if b, err = strconv.ParseFloat(im, 64); err != nil {
+						return nil, fmt.Errorf("%w: invalid value %q", ErrParsingError, field)
+					}
+				}
+				point[i-1] = complex(a, b)
+				seen++
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	if len(x) == 0 {
+		return nil, fmt.Errorf("%w: no data", ErrParsingError)
+	}
+
+	simType, err := simTypeFromString(analysis)
+	if err != nil {
+		simType = xyceSimType(XyceTraceName(sweepVar))
+	}
+	xName := XyceTraceName(sweepVar)
+	switch simType {
+	case TransientAnalysis:
+		xName = "time"
+	case ACAnalysis:
+		xName = "frequency"
+	}
+	traces := []string{xName}
+	for _, name := range names {
+		traces = append(traces, XyceTraceName(name))
+	}
+	if isComplex {
+		columns := [][]complex128{make([]complex128, len(x))}
+		for i, v := range x {
+			columns[0][i] = complex(v, 0)
+		}
+		return newTableSim(simType, title, traces, nil, append(columns, values...))
+	}
+	columns := [][]float64{x}
+	for _, v := range values {
+		column := make([]float64, len(v))
+		for i := range v {
+			column[i] = real(v[i])
+		}
+		columns = append(columns, column)
+	}
+	return newTableSim(simType, title, traces, columns, nil)
+}
+
+// csdHeaderFields parses the KEY='value' pairs of a probe header line.
+func csdHeaderFields(line string) map[string]string {
+	fields := map[string]string{}
+	for line != "" {
+		key, rest, ok := strings.Cut(line, "='")
+		if !ok {
+			break
+		}
+		value, rest, _ := strings.Cut(rest, "'")
+		fields[strings.ToUpper(strings.TrimSpace(key))] = value
+		line = strings.TrimLeftFunc(rest, unicode.IsSpace)
+	}
+	return fields
+}
+
+// csdNames returns the quoted names of a #N line.
+func csdNames(line string) []string {
+	var names []string
+	for {
+		start := strings.IndexByte(line, '\'')
+		if start < 0 {
+			return names
+		}
+		end := strings.IndexByte(line[start+1:], '\'')
+		if end < 0 {
+			return names
+		}
+		names = append(names, line[start+1:start+1+end])
+		line = line[start+end+2:]
+	}
+}
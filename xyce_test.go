@@ -0,0 +1,110 @@
+package ltspice
+
+import (
+	"math"
+	"math/cmplx"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestXyceTraceName(t *testing.T) {
+	tests := map[string]string{
+		"TIME":      "time",
+		"FREQ":      "frequency",
+		"V(OUT)":    "V(out)",
+		"v(N001,0)": "V(n001,0)",
+		"I(R1)":     "I(R1)",
+		"I(VIN)":    "I(Vin)",
+		"IC(Q1)":    "Ic(Q1)",
+		"V1#branch": "I(V1)",
+		"V1":        "v1",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, XyceTraceName(in), in)
+	}
+}
+
+func TestParseXycePRN(t *testing.T) {
+	sim, err := ParseXyce("testdata/simulations/xyce/rc.cir.prn")
+	require.NoError(t, err)
+	assert.Equal(t, TransientAnalysis, sim.GetType())
+	assert.Equal(t, []string{"time", "V(in)", "V(out)", "I(R1)"}, variableNames(sim))
+	assert.Equal(t, "device_current", sim.GetVariables()[3].Typ)
+	require.Equal(t, 2, sim.GetSteps())
+	assert.True(t, sim.Meta.Flags.hasFlag(Stepped))
+
+	vout, err := GetTrace[float64](sim, "V(out)")
+	require.NoError(t, err)
+	assert.InDelta(t, 1-math.Exp(-1), vout.GetSignal(0)[5], 1e-8)
+	assert.InDelta(t, 1-math.Exp(-0.5), vout.GetSignal(1)[5], 1e-8)
+
+	ac, err := ParseXyce("testdata/simulations/xyce/rc-ac.cir.prn")
+	require.NoError(t, err)
+	assert.Equal(t, ACAnalysis, ac.GetType())
+	assert.Equal(t, []string{"frequency", "V(out)", "V(in)"}, variableNames(ac))
+	c, err := GetTrace[complex128](ac, "V(out)")
+	require.NoError(t, err)
+	// -3 dB and -45° at 1/(2*pi*RC)
+	f := ac.GetXAxis()
+	for i, v := range c.GetSignal() {
+		assert.InDelta(t, 0, cmplx.Abs(v-1/complex(1, 2*math.Pi*f[i]*1e-3)), 1e-8)
+	}
+	assert.Equal(t, complex(1, 0), ac.complexData["V(in)"][0])
+}
+
+func TestParseXycePRNErrors(t *testing.T) {
+	_, err := ParseXycePRN(strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrParsingError)
+	_, err = ParseXycePRN(strings.NewReader("Index TIME V(OUT)\n0 0 1 2\n"))
+	assert.ErrorIs(t, err, ErrParsingError)
+	_, err = ParseXycePRN(strings.NewReader("Index TIME V(OUT)\n0 0 abc\n"))
+	assert.ErrorIs(t, err, ErrParsingError)
+
+	sim, err := ParseXycePRN(strings.NewReader("V1,V(OUT)\n0,0\n1,0.5\n"))
+	require.NoError(t, err)
+	assert.Equal(t, DCtransfer, sim.GetType())
+	assert.Equal(t, []float64{0, 1}, sim.GetXAxis())
+}
+
+func TestParseXyceCSD(t *testing.T) {
+	sim, err := ParseXyce("testdata/simulations/xyce/rc.cir.csd")
+	require.NoError(t, err)
+	assert.Equal(t, TransientAnalysis, sim.GetType())
+	assert.Equal(t, "* rc low pass", sim.Meta.Title)
+	assert.Equal(t, []string{"time", "V(in)", "V(out)", "I(R1)"}, variableNames(sim))
+	assert.Equal(t, 11, sim.Meta.NoPoints)
+	assert.InDelta(t, 1-math.Exp(-1), sim.data["V(out)"][5], 1e-8)
+
+	ac, err := ParseXyce("testdata/simulations/xyce/rc-ac.cir.csd")
+	require.NoError(t, err)
+	assert.Equal(t, ACAnalysis, ac.GetType())
+	assert.True(t, ac.Meta.Flags.hasFlag(Complex))
+	assert.InDelta(t, 1000, ac.GetXAxis()[4], 1e-6)
+	assert.InDelta(t, 1/math.Sqrt(1+4*math.Pi*math.Pi), cmplx.Abs(ac.complexData["V(out)"][4]), 1e-8)
+
+	_, err = ParseXyceCSD(strings.NewReader("#H\nANALYSIS='Transient Analysis'\n#N\n'V(A)'\n#C 0 1\n1:2\n#;\n"))
+	assert.ErrorIs(t, err, ErrParsingError)
+}
+
+func TestParseXyceRaw(t *testing.T) {
+	sim, err := ParseXyce("testdata/simulations/xyce/rc.cir.raw")
+	require.NoError(t, err)
+	assert.Equal(t, TransientAnalysis, sim.GetType())
+	assert.Equal(t, []string{"time", "V(in)", "V(out)", "I(V1)"}, variableNames(sim))
+	assert.Equal(t, 2e-4, sim.GetXAxis()[1])
+
+	// the raw and .prn output map to the same trace names
+	prn, err := ParseXyce("testdata/simulations/xyce/rc.cir.prn")
+	require.NoError(t, err)
+	prn, err = prn.SelectSteps(0)
+	require.NoError(t, err)
+	report, err := Compare(sim, prn, CompareOptions{
+		Tolerance: Tolerance{Abs: 1e-8},
+		Traces:    map[string]*Tolerance{"V(out)": nil},
+	})
+	require.NoError(t, err)
+	assert.True(t, report.Passed())
+}